@@ -0,0 +1,103 @@
+package chinrem
+
+import (
+	"fmt"
+)
+
+// BatchInvError is returned by BatchInv when some of the inputs have no inverse.
+// It wraps ErrNotInversible.
+type BatchInvError struct {
+	Indexes []int // indexes of the inputs that were not inversible, in increasing order
+}
+
+func (err *BatchInvError) Error() string {
+	return fmt.Sprintf("%v : %d input(s) at indexes %v", ErrNotInversible, len(err.Indexes), err.Indexes)
+}
+
+func (err *BatchInvError) Unwrap() error {
+	return ErrNotInversible
+}
+
+// BatchInv computes dst[j], the inverse of src[j], for all j, using the Montgomery simultaneous inversion trick.
+// For each lane, a single modular inversion is computed, plus 3 multiplications per input.
+// If some inputs are not inversible, the others are still computed, the corresponding dst are left untouched,
+// and a *BatchInvError is returned, listing them.
+// The slices should have the same length, and all CRI should belong to e.
+// Aliasing is allowed : dst[j] can be any of the src, but the dst should be distinct CRIs.
+// Normalization is assumed, and dst are normalized.
+func (e *CREngine) BatchInv(dst, src []*CRI) error {
+	if len(dst) != len(src) {
+		panic("BatchInv slices lengths do not match")
+	}
+
+	// First, find the inputs that are not inversible.
+	bad := make([]bool, len(src))
+	var indexes []int
+	for j, c := range src {
+		for i, r := range c.rm {
+			if !e.isUnit(r, i) {
+				bad[j] = true
+				indexes = append(indexes, j)
+				break
+			}
+		}
+	}
+
+	// Then, invert all the other ones, lane by lane.
+	run := func(lo, hi int) {
+		prefix, vals := make([]int64, len(src)), make([]int64, len(src))
+		for i := lo; i < hi; i++ {
+			e.batchInvLane(i, dst, src, bad, prefix, vals)
+		}
+	}
+	if e.serial(len(src) * e.size) {
+		run(0, e.size)
+	} else {
+		e.shard(e.size, run)
+	}
+
+	if len(indexes) != 0 {
+		return &BatchInvError{Indexes: indexes}
+	}
+	return nil
+}
+
+// isUnit is true if r is inversible modulo primes[i].
+func (e *CREngine) isUnit(r int64, i int) bool {
+	if f := e.factors[i]; len(f) == 1 && f[0].k == 1 {
+		return r != 0 // prime modulus
+	}
+	g, _, _ := gcd(r, e.primes[i])
+	return g == 1
+}
+
+// batchInvLane applies the Montgomery trick to lane i, skipping the bad inputs.
+// prefix and vals are buffers, as large as src.
+func (e *CREngine) batchInvLane(i int, dst, src []*CRI, bad []bool, prefix, vals []int64) {
+	m := e.primes[i]
+
+	// vals[j] is src[j].rm[i], read before any dst is written, since dst may alias src at other indexes.
+	// prefix[j] is the product of all the good vals[k], for k <= j.
+	acc := 1 % m
+	for j, c := range src {
+		vals[j] = c.rm[i]
+		if !bad[j] {
+			acc = mulmod(acc, vals[j], m)
+		}
+		prefix[j] = acc
+	}
+
+	// inv is the inverse of prefix[j], going backwards.
+	inv := invmod(acc, m)
+	for j := len(src) - 1; j >= 0; j-- {
+		if bad[j] {
+			continue
+		}
+		before := 1 % m
+		if j > 0 {
+			before = prefix[j-1]
+		}
+		dst[j].rm[i] = mulmod(inv, before, m)
+		inv = mulmod(inv, vals[j], m)
+	}
+}
@@ -0,0 +1,98 @@
+package chinrem
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+func TestBatchInv(t *testing.T) {
+	rd := rand.New(rand.NewSource(42))
+	m9, _ := NewCREngineModuli([]int64{9, 4, 25, 7})
+
+	for _, e := range []*CREngine{NewCREngine(5), NewCREngine62(3), m9, NewCREngine(20).Parallel(3, 1)} {
+		const n = 100
+		src, dst := make([]*CRI, n), make([]*CRI, n)
+		var want []int
+		for j := range src {
+			src[j], dst[j] = e.NewCRIRand(rd), e.NewCRIInt64(12345)
+			if j%7 == 3 {
+				src[j].rm[j%e.size] = 0
+			}
+			if e.NewCRI().Inv(src[j]) != nil {
+				want = append(want, j)
+			}
+		}
+		if len(want) == 0 || len(want) == n {
+			t.Fatalf("test should mix inversible and non inversible inputs : %d", len(want))
+		}
+
+		err := e.BatchInv(dst, src)
+		var be *BatchInvError
+		if !errors.As(err, &be) || !errors.Is(err, ErrNotInversible) {
+			t.Fatalf("unexpected error : %v", err)
+		}
+		if fmt.Sprint(be.Indexes) != fmt.Sprint(want) {
+			t.Fatalf("unexpected indexes : got %v, want %v", be.Indexes, want)
+		}
+
+		for j := range src {
+			inv := e.NewCRI()
+			if inv.Inv(src[j]) != nil {
+				if !dst[j].Equal(e.NewCRIInt64(12345)) {
+					t.Fatalf("dst[%d] should be untouched", j)
+				}
+			} else if !dst[j].Equal(inv) {
+				t.Fatalf("dst[%d] : got %v, want %v", j, dst[j], inv)
+			}
+		}
+
+		// aliasing, all inversible
+		good, check := []*CRI{}, []*CRI{}
+		for j := range src {
+			if dst[j].Mul(dst[j], src[j]).IsOne() {
+				good = append(good, src[j])
+				check = append(check, src[j].Clone())
+			}
+		}
+		if err := e.BatchInv(good, good); err != nil {
+			t.Fatal(err)
+		}
+		for j := range good {
+			if !good[j].Mul(good[j], check[j]).IsOne() {
+				t.Fatalf("aliased BatchInv failed for %v", check[j])
+			}
+		}
+
+		// aliasing across indexes : dst[j] is src[k-1-j]
+		k := len(good)
+		rev := make([]*CRI, k)
+		for j := range good {
+			rev[j] = good[k-1-j]
+			check[j] = good[j].Clone()
+		}
+		if err := e.BatchInv(rev, good); err != nil {
+			t.Fatal(err)
+		}
+		for j := range rev {
+			if !rev[j].Mul(rev[j], check[j]).IsOne() {
+				t.Fatalf("cross aliased BatchInv failed for %v", check[j])
+			}
+		}
+	}
+}
+
+func TestBatchInvReversed(t *testing.T) {
+	e := NewCREngine(5)
+	src := []*CRI{e.NewCRIInt64(13), e.NewCRIInt64(17), e.NewCRIInt64(19), e.NewCRIInt64(23)}
+	dst := []*CRI{src[3], src[2], src[1], src[0]}
+	if err := e.BatchInv(dst, src); err != nil {
+		t.Fatal(err)
+	}
+	for j, v := range []int64{13, 17, 19, 23} {
+		if !dst[j].Mul(dst[j], e.NewCRIInt64(v)).IsOne() {
+			t.Fatalf("dst[%d] is not the inverse of %d", j, v)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package chinrem
 import (
 	"fmt"
 	"math/big"
+	"math/rand"
 	"testing"
 	"time"
 )
@@ -79,3 +80,184 @@ func BenchmarkBvC(b *testing.B) {
 	})
 
 }
+
+func BenchmarkToBig(b *testing.B) {
+
+	b.Run("coprimes", func(bb *testing.B) {
+		reset()
+		bb.ResetTimer()
+
+		for i := 1; i < bb.N; i++ {
+			b3 = c1.toBigCoprimes()
+		}
+	})
+
+	b.Run("mixedradix", func(bb *testing.B) {
+		reset()
+		bb.ResetTimer()
+
+		for i := 1; i < bb.N; i++ {
+			b3 = c1.ToBig()
+		}
+	})
+}
+
+func BenchmarkWidth(b *testing.B) {
+
+	e1 := NewCREngine(100)
+	e2 := NewCREngine62(e1.Limit().BitLen()/62 + 1) // about the same limit, with far fewer lanes
+	rd := rand.New(rand.NewSource(42))
+
+	for _, en := range []*CREngine{e1, e2} {
+		x, y := en.NewCRIRand(rd), en.NewCRIRand(rd)
+		name := fmt.Sprintf("%d-lanes", en.size)
+
+		b.Run(name+".mul", func(bb *testing.B) {
+			for i := 1; i < bb.N; i++ {
+				x.Mul(x, y)
+			}
+		})
+
+		b.Run(name+".tobig", func(bb *testing.B) {
+			for i := 1; i < bb.N; i++ {
+				b3 = x.ToBig()
+			}
+		})
+	}
+}
+
+// parallelSizes are the numbers of residues processed by each operation in BenchmarkParallel, on both sides of DefaultThreshold.
+var parallelSizes = []int{512, 1024, 2048, 4096, 8192}
+
+// parallelBatchLanes is the size of the engine used for the batch operations in BenchmarkParallel.
+// The batches hold size/parallelBatchLanes CRIs, so that they process the same number of residues as the other operations.
+const parallelBatchLanes = 16
+
+// parallelOperands are the operands of BenchmarkParallel : x, y, z and the exponent n on an engine of the benchmarked size,
+// and batches of CRIs, as slices and as vectors, on an engine of parallelBatchLanes.
+type parallelOperands struct {
+	x, y, z, n *CRI
+	xs, ys, zs []*CRI
+	xv, yv, zv *CRIVector
+}
+
+// newParallelOperands creates random operands, with no zero residue, so that they are inversible on any lane.
+func newParallelOperands(en, bn *CREngine, size int, rd *rand.Rand) *parallelOperands {
+	unit := func(e *CREngine) *CRI {
+		c := e.NewCRIRand(rd)
+		for i, r := range c.rm {
+			if r == 0 {
+				c.rm[i] = 1
+			}
+		}
+		return c
+	}
+	nb := size / parallelBatchLanes
+	o := &parallelOperands{x: unit(en), y: unit(en), z: en.NewCRI(), n: en.NewCRIInt64(654789),
+		xs: make([]*CRI, nb), ys: make([]*CRI, nb), zs: make([]*CRI, nb)}
+	for j := range o.xs {
+		o.xs[j], o.ys[j], o.zs[j] = unit(bn), unit(bn), bn.NewCRI()
+	}
+	o.xv, o.yv, o.zv = bn.NewCRIVector(nb).Gather(o.xs), bn.NewCRIVector(nb).Gather(o.ys), bn.NewCRIVector(nb)
+	return o
+}
+
+// BenchmarkParallel compares serial and parallel engines for every sharded operation, to find the crossover point.
+// The parallel engine uses GOMAXPROCS workers, and a threshold of 1 so that it never falls back to serial.
+// Vary the number of workers with -cpu, such as : go test -run XXX -bench Parallel -cpu 1,2,4,8
+func BenchmarkParallel(b *testing.B) {
+
+	rd := rand.New(rand.NewSource(42))
+	n := big.NewInt(654789)
+
+	ops := []struct {
+		name string
+		f    func(o *parallelOperands, bn *CREngine)
+	}{
+		{"add", func(o *parallelOperands, _ *CREngine) { o.z.Add(o.x, o.y) }},
+		{"mul", func(o *parallelOperands, _ *CREngine) { o.z.Mul(o.x, o.y) }},
+		{"inv", func(o *parallelOperands, _ *CREngine) { o.z.Inv(o.x) }},
+		{"expi", func(o *parallelOperands, _ *CREngine) { o.z.ExpI(o.x, n.Int64()) }},
+		{"exp", func(o *parallelOperands, _ *CREngine) { o.z.Exp(o.x, n) }},
+		{"expcri", func(o *parallelOperands, _ *CREngine) { o.z.ExpCRI(o.x, o.n) }},
+		{"tobig", func(o *parallelOperands, _ *CREngine) { b3 = o.x.ToBig() }},
+		{"batchadd", func(o *parallelOperands, bn *CREngine) { bn.BatchAdd(o.zs, o.xs, o.ys) }},
+		{"batchmul", func(o *parallelOperands, bn *CREngine) { bn.BatchMul(o.zs, o.xs, o.ys) }},
+		{"batchtobig", func(o *parallelOperands, bn *CREngine) { bn.BatchToBig(o.xs) }},
+		{"batchinv", func(o *parallelOperands, bn *CREngine) { bn.BatchInv(o.zs, o.xs) }},
+		{"vectormul", func(o *parallelOperands, _ *CREngine) { o.zv.Mul(o.xv, o.yv) }},
+	}
+
+	for _, size := range parallelSizes {
+		es, eb := NewCREngine(size), NewCREngine(parallelBatchLanes)
+
+		for _, op := range ops {
+			for _, mode := range []string{"serial", "parallel"} {
+				b.Run(fmt.Sprintf("%s/%d-residues/%s", op.name, size, mode), func(bb *testing.B) {
+					en, bn := es, eb
+					if mode == "parallel" { // GOMAXPROCS, as set by -cpu, is only known here
+						en, bn = es.Parallel(0, 1), eb.Parallel(0, 1)
+					}
+					o := newParallelOperands(en, bn, size, rd)
+					bb.ResetTimer()
+					for i := 0; i < bb.N; i++ {
+						op.f(o, bn)
+					}
+				})
+			}
+		}
+	}
+}
+
+// BenchmarkVector compares element-wise multiplication of a []*CRI with a CRIVector.
+func BenchmarkVector(b *testing.B) {
+
+	const n = 10000
+	rd := rand.New(rand.NewSource(42))
+	en := NewCREngine(20)
+	xs, ys := make([]*CRI, n), make([]*CRI, n)
+	for j := range xs {
+		xs[j], ys[j] = en.NewCRIRand(rd), en.NewCRIRand(rd)
+	}
+	xv, yv := en.NewCRIVector(n).Gather(xs), en.NewCRIVector(n).Gather(ys)
+
+	b.Run("slice.mul", func(bb *testing.B) {
+		for i := 1; i < bb.N; i++ {
+			for j, x := range xs {
+				x.Mul(x, ys[j])
+			}
+		}
+	})
+
+	b.Run("vector.mul", func(bb *testing.B) {
+		for i := 1; i < bb.N; i++ {
+			xv.Mul(xv, yv)
+		}
+	})
+}
+
+// BenchmarkBatchInv compares inverting a slice of CRI one by one, or with BatchInv.
+func BenchmarkBatchInv(b *testing.B) {
+
+	const n = 1000
+	rd := rand.New(rand.NewSource(42))
+	en := NewCREngine62(10)
+	src, dst := make([]*CRI, n), make([]*CRI, n)
+	for j := range src {
+		src[j], dst[j] = en.NewCRIRand(rd), en.NewCRI()
+	}
+
+	b.Run("inv", func(bb *testing.B) {
+		for i := 1; i < bb.N; i++ {
+			for j, c := range src {
+				dst[j].Inv(c)
+			}
+		}
+	})
+
+	b.Run("batchinv", func(bb *testing.B) {
+		for i := 1; i < bb.N; i++ {
+			en.BatchInv(dst, src)
+		}
+	})
+}
@@ -0,0 +1,175 @@
+package chinrem
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// The checked API mirrors the main operations, but validates its arguments and returns errors instead of panicking,
+// or silently computing garbage when CRIs from different engines are mixed.
+// Each Try method checks that all the CRIs share the base of c, as SameEngine, then calls the corresponding operation.
+
+var ErrEngineMismatch = fmt.Errorf("engine mismatch")
+var ErrLengthMismatch = fmt.Errorf("length mismatch")
+var ErrUndefined = fmt.Errorf("undefined result")
+
+// check returns an error wrapping ErrEngineMismatch, unless c and all the provided CRIs share the same base.
+func (c *CRI) check(xs ...*CRI) error {
+	if c == nil || c.e == nil {
+		return fmt.Errorf("%w : the target CRI has no engine", ErrEngineMismatch)
+	}
+	for i, x := range xs {
+		if !SameEngine(c, x) {
+			return fmt.Errorf("%w : argument %d does not belong to the engine %016x", ErrEngineMismatch, i, c.e.fingerprint)
+		}
+	}
+	return nil
+}
+
+// checkLen returns an error wrapping ErrLengthMismatch, unless n matches the size of the engine of c.
+func (c *CRI) checkLen(n int) error {
+	if err := c.check(); err != nil {
+		return err
+	}
+	if n != c.e.size {
+		return fmt.Errorf("%w : got %d values, but the engine has %d moduli", ErrLengthMismatch, n, c.e.size)
+	}
+	return nil
+}
+
+// TrySet is the checked version of Set.
+func (c *CRI) TrySet(a *CRI) error {
+	if err := c.check(a); err != nil {
+		return err
+	}
+	c.Set(a)
+	return nil
+}
+
+// TrySetSlice is the checked version of SetSlice.
+func (c *CRI) TrySetSlice(value []int64) error {
+	if err := c.checkLen(len(value)); err != nil {
+		return err
+	}
+	c.SetSlice(value)
+	return nil
+}
+
+// TrySetMixedRadix is the checked version of SetMixedRadix.
+func (c *CRI) TrySetMixedRadix(digits []int64) error {
+	if err := c.checkLen(len(digits)); err != nil {
+		return err
+	}
+	c.SetMixedRadix(digits)
+	return nil
+}
+
+// TryCmp is the checked version of Cmp.
+func (c *CRI) TryCmp(a *CRI) (int, error) {
+	if err := c.check(a); err != nil {
+		return 0, err
+	}
+	return c.Cmp(a), nil
+}
+
+// TryAdd is the checked version of Add.
+func (c *CRI) TryAdd(a, b *CRI) error {
+	if err := c.check(a, b); err != nil {
+		return err
+	}
+	c.Add(a, b)
+	return nil
+}
+
+// TrySub is the checked version of Sub.
+func (c *CRI) TrySub(a, b *CRI) error {
+	if err := c.check(a, b); err != nil {
+		return err
+	}
+	c.Sub(a, b)
+	return nil
+}
+
+// TryNeg is the checked version of Neg.
+func (c *CRI) TryNeg(a *CRI) error {
+	if err := c.check(a); err != nil {
+		return err
+	}
+	c.Neg(a)
+	return nil
+}
+
+// TryMul is the checked version of Mul.
+func (c *CRI) TryMul(a, b *CRI) error {
+	if err := c.check(a, b); err != nil {
+		return err
+	}
+	c.Mul(a, b)
+	return nil
+}
+
+// TryInv is the checked version of Inv.
+func (c *CRI) TryInv(a *CRI) error {
+	if err := c.check(a); err != nil {
+		return err
+	}
+	return c.Inv(a)
+}
+
+// TryQuo is the checked version of Quo.
+func (c *CRI) TryQuo(a, b *CRI) error {
+	if err := c.check(a, b); err != nil {
+		return err
+	}
+	return c.Quo(a, b)
+}
+
+// TryDivMod is the checked version of DivMod.
+func (c *CRI) TryDivMod(a, b, m *CRI) error {
+	if err := c.check(a, b, m); err != nil {
+		return err
+	}
+	if c == m {
+		return fmt.Errorf("%w : quotient and remainder should be different", ErrUndefined)
+	}
+	return c.DivMod(a, b, m)
+}
+
+// TryExp is the checked version of Exp.
+// A negative power of a non inversible value returns an error wrapping both ErrUndefined and ErrNotInversible, leaving c unchanged.
+func (c *CRI) TryExp(a *CRI, n *big.Int) error {
+	if err := c.check(a); err != nil {
+		return err
+	}
+	if n.Sign() < 0 {
+		if err := c.e.NewCRI().Inv(a); err != nil {
+			return fmt.Errorf("%w : negative power of a non inversible value : %w", ErrUndefined, err)
+		}
+	}
+	c.Exp(a, n)
+	return nil
+}
+
+// TryExpI is the checked version of ExpI.
+// A negative power of a non inversible value returns an error wrapping both ErrUndefined and ErrNotInversible, leaving c unchanged.
+func (c *CRI) TryExpI(a *CRI, n int64) error {
+	if err := c.check(a); err != nil {
+		return err
+	}
+	if n < 0 {
+		if err := c.e.NewCRI().Inv(a); err != nil {
+			return fmt.Errorf("%w : negative power of a non inversible value : %w", ErrUndefined, err)
+		}
+	}
+	c.ExpI(a, n)
+	return nil
+}
+
+// TryExpCRI is the checked version of ExpCRI.
+func (c *CRI) TryExpCRI(a, n *CRI) error {
+	if err := c.check(a, n); err != nil {
+		return err
+	}
+	c.ExpCRI(a, n)
+	return nil
+}
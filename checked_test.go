@@ -0,0 +1,79 @@
+package chinrem
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func TestChecked(t *testing.T) {
+	e := NewCREngine(10)
+	other := NewCREngineWidth(10, Primes31) // same size, different base
+	pe := e.Parallel(2, 1)                  // same base
+
+	a, b, c := e.NewCRIInt64(12), pe.NewCRIInt64(31), e.NewCRI()
+	x := other.NewCRIInt64(5)
+
+	if !SameEngine(a, b) || SameEngine(a, x) || SameEngine(a, nil) {
+		t.Fatal("unexpected SameEngine results")
+	}
+	if a.Cmp(x) != -x.Cmp(a) {
+		t.Fatal("Cmp across engines should stay antisymmetric")
+	}
+
+	for name, op := range map[string]func(x *CRI) error{
+		"TrySet":    func(x *CRI) error { return c.TrySet(x) },
+		"TryAdd":    func(x *CRI) error { return c.TryAdd(a, x) },
+		"TrySub":    func(x *CRI) error { return c.TrySub(x, a) },
+		"TryNeg":    func(x *CRI) error { return c.TryNeg(x) },
+		"TryMul":    func(x *CRI) error { return c.TryMul(a, x) },
+		"TryInv":    func(x *CRI) error { return c.TryInv(x) },
+		"TryQuo":    func(x *CRI) error { return c.TryQuo(a, x) },
+		"TryDivMod": func(x *CRI) error { return c.TryDivMod(a, x, e.NewCRI()) },
+		"TryExp":    func(x *CRI) error { return c.TryExp(x, big.NewInt(3)) },
+		"TryExpI":   func(x *CRI) error { return c.TryExpI(x, 3) },
+		"TryExpCRI": func(x *CRI) error { return c.TryExpCRI(a, x) },
+		"TryCmp":    func(x *CRI) error { _, err := c.TryCmp(x); return err },
+	} {
+		if err := op(b); err != nil {
+			t.Fatalf("%s should accept a CRI from a parallel engine : %v", name, err)
+		}
+		if err := op(x); !errors.Is(err, ErrEngineMismatch) {
+			t.Fatalf("%s should reject a CRI from another engine : %v", name, err)
+		}
+		if err := op(nil); !errors.Is(err, ErrEngineMismatch) {
+			t.Fatalf("%s should reject a nil CRI : %v", name, err)
+		}
+	}
+
+	if err := c.TryMul(a, b); err != nil || c.ToBig().Int64() != 372 {
+		t.Fatal("TryMul failed", err)
+	}
+	if err := c.TrySetSlice([]int64{1, 2}); !errors.Is(err, ErrLengthMismatch) {
+		t.Fatal("TrySetSlice should fail", err)
+	}
+	if err := c.TrySetMixedRadix(make([]int64, 11)); !errors.Is(err, ErrLengthMismatch) {
+		t.Fatal("TrySetMixedRadix should fail", err)
+	}
+	if err := c.TrySetSlice(make([]int64, 10)); err != nil || !c.IsZero() {
+		t.Fatal("TrySetSlice failed", err)
+	}
+
+	c.SetInt64(7)
+	err := c.TryExpI(a, -1)
+	if !errors.Is(err, ErrUndefined) || !errors.Is(err, ErrNotInversible) || c.ToBig().Int64() != 7 {
+		t.Fatal("TryExpI should fail for a negative power of a non inversible value", err)
+	}
+	if err := c.TryExp(a, big.NewInt(-2)); !errors.Is(err, ErrUndefined) {
+		t.Fatal("TryExp should fail for a negative power of a non inversible value", err)
+	}
+	if err := c.TryExpI(e.NewCRIInt64(31), -1); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.TryDivMod(a, b, c); !errors.Is(err, ErrUndefined) {
+		t.Fatal("TryDivMod should reject the same quotient and remainder", err)
+	}
+	if err := new(CRI).TryAdd(a, b); !errors.Is(err, ErrEngineMismatch) {
+		t.Fatal("a CRI without engine should be rejected", err)
+	}
+}
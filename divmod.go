@@ -0,0 +1,117 @@
+package chinrem
+
+import (
+	"math"
+	"math/big"
+)
+
+// Integer division stays in residue form. The quotient is built by successive approximations :
+// the magnitudes of the remainder and of the divisor are estimated as floats from their mixed-radix digits,
+// and a slightly underestimated partial quotient is subtracted, until the remainder is smaller than the divisor.
+// Each step gains about 50 - log2(number of groups) bits on the quotient.
+
+// approxSafety makes partial quotients slightly too small, to absorb the rounding errors of approx,
+// on both the remainder and the divisor : it grows with the number of groups, as approxTolerance.
+func (e *CREngine) approxSafety() float64 {
+	return 1 - 2*e.approxTolerance()
+}
+
+// approx returns f and exp, such that c is about f * 2^exp, with 0.5 <= f < 1, or f = 0 if c is 0.
+// The value of c is taken in [0, Limit). The relative error is below (number of groups) * 2^-52.
+// Normalization is not required.
+func (c *CRI) approx() (f float64, exp int) {
+	d := c.groupDigits(make([]uint64, len(c.e.mrGroups)))
+	for g := len(d) - 1; g >= 0; g-- {
+		// f*2^exp = f*2^exp*m + d[g]
+		f = f*float64(c.e.mrGroups[g].m) + math.Ldexp(float64(d[g]), -exp)
+		var x int
+		f, x = math.Frexp(f)
+		exp += x
+	}
+	if f == 0 {
+		exp = 0
+	}
+	return f, exp
+}
+
+// setApprox sets c to floor(f * 2^exp), for f >= 0, that should be smaller than Limit.
+func (c *CRI) setApprox(f float64, exp int) *CRI {
+	f, x := math.Frexp(f)
+	exp += x - 53
+	mant := int64(math.Ldexp(f, 53))
+	if exp <= 0 {
+		if exp <= -63 {
+			mant = 0
+		} else {
+			mant >>= uint(-exp)
+		}
+		return c.SetInt64(mant)
+	}
+	for i, p := range c.e.primes {
+		c.rm[i] = mulmod(mant%p, expi(2, int64(exp), p), p)
+	}
+	return c
+}
+
+// DivMod computes the integer quotient q = floor(a/b), stored into c, and the remainder a - q*b, stored into m.
+// a and b are taken as values in [0, Limit), and 0 <= m < b.
+// It returns ErrDivideByZero if b is 0.
+// c and m should be different.
+func (c *CRI) DivMod(a, b, m *CRI) error {
+	if b.IsZero() {
+		return ErrDivideByZero
+	}
+	e := c.e
+	q, r, t := e.NewCRI(), a.Clone(), e.NewCRI()
+	bb := b.Clone()
+	fb, xb := bb.approx()
+	safety := e.approxSafety()
+
+	for r.Cmp(bb) >= 0 {
+		// r >= b, so the partial quotient is at least 1.
+		fr, xr := r.approx()
+		est := fr / fb * safety
+		if math.Ldexp(est, xr-xb) < 1 {
+			t.SetInt64(1)
+		} else {
+			t.setApprox(est, xr-xb)
+		}
+		q.Add(q, t)
+		r.Sub(r, t.Mul(t, bb))
+	}
+	c.Set(q)
+	m.Set(r)
+	return nil
+}
+
+// Rem returns the remainder of c divided by m, with c taken in [0, Limit).
+// m should be strictly positive.
+func (c *CRI) Rem(m int64) int64 {
+	if m <= 0 {
+		panic("Rem requires a strictly positive modulus")
+	}
+	d := c.groupDigits(make([]uint64, len(c.e.mrGroups)))
+	var y int64
+	for g := len(d) - 1; g >= 0; g-- {
+		y = mulAddMod(y, int64(c.e.mrGroups[g].m%uint64(m)), int64(d[g]%uint64(m)), m)
+	}
+	return y
+}
+
+// ModBig computes a modulo m, and stores it in c, returning c. a is taken in [0, Limit).
+// m should be strictly positive.
+func (c *CRI) ModBig(a *CRI, m *big.Int) *CRI {
+	if m.Sign() <= 0 {
+		panic("ModBig requires a strictly positive modulus")
+	}
+	if m.Cmp(c.e.limit) >= 0 {
+		return c.Set(a)
+	}
+	if m.IsInt64() {
+		return c.SetInt64(a.Rem(m.Int64()))
+	}
+	if err := c.e.NewCRI().DivMod(a, c.e.NewCRIBig(m), c); err != nil {
+		panic(err) // m is not 0
+	}
+	return c
+}
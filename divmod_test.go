@@ -0,0 +1,78 @@
+package chinrem
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+)
+
+func TestApprox(t *testing.T) {
+	rd := rand.New(rand.NewSource(42))
+	for _, e := range []*CREngine{NewCREngine(100), NewCREngine62(20), NewCREngine(3)} {
+		for i := 0; i < 100; i++ {
+			a := e.NewCRIRand(rd)
+			if i%10 == 0 {
+				a.SetInt64(int64(i))
+			}
+			f, exp := a.approx()
+			got := new(big.Float).SetMantExp(big.NewFloat(f), exp)
+			want := new(big.Float).SetInt(a.ToBig())
+			if want.Sign() == 0 {
+				if f != 0 {
+					t.Fatalf("approx of 0 : got %v", got)
+				}
+				continue
+			}
+			diff := new(big.Float).Sub(got, want)
+			diff.Quo(diff, want)
+			if d, _ := diff.Float64(); d > e.approxTolerance() || d < -e.approxTolerance() {
+				t.Fatalf("approx of %v : got %v, relative error %v", want, got, d)
+			}
+		}
+	}
+}
+
+func TestDivMod(t *testing.T) {
+	rd := rand.New(rand.NewSource(42))
+	pp, _ := NewCREngineModuli([]int64{64, 81, 25, 49, 11, 13, 17, 19, 23})
+
+	for _, e := range []*CREngine{NewCREngine(40), NewCREngine62(5), pp} {
+		for i := 0; i < 200; i++ {
+			a := e.NewCRIRand(rd)
+			b := e.NewCRIBig(new(big.Int).Rand(rd, new(big.Int).Rsh(e.Limit(), uint(rd.Intn(e.Limit().BitLen())))))
+			if b.IsZero() {
+				continue
+			}
+			if i%10 == 0 {
+				b.Set(a)
+			}
+			ab, bb := a.ToBig(), b.ToBig()
+			wq, wr := new(big.Int).DivMod(ab, bb, new(big.Int))
+
+			q, r := e.NewCRI(), e.NewCRI()
+			if err := q.DivMod(a, b, r); err != nil {
+				t.Fatal(err)
+			}
+			checkBig(t, "DivMod quotient", q, wq)
+			checkBig(t, "DivMod remainder", r, wr)
+
+			// aliasing
+			if a.Clone().DivMod(a, b, b.Clone()); !a.Equal(e.NewCRIBig(ab)) {
+				t.Fatal("DivMod modified a")
+			}
+			checkBig(t, "ModBig", e.NewCRI().ModBig(a, bb), wr)
+			checkBig(t, "ModBig aliased", a.Clone().ModBig(a.Clone(), bb), wr)
+
+			m := rd.Int63n(1<<62) + 1
+			if i%3 == 0 {
+				m = rd.Int63n(1000) + 1
+			}
+			if got, want := a.Rem(m), new(big.Int).Mod(ab, big.NewInt(m)).Int64(); got != want {
+				t.Fatalf("Rem %v %% %d : got %d, want %d", ab, m, got, want)
+			}
+		}
+		if err := e.NewCRI().DivMod(e.NewCRIInt64(5), e.NewCRI(), e.NewCRI()); err != ErrDivideByZero {
+			t.Fatal("DivMod by 0 should fail", err)
+		}
+	}
+}
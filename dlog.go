@@ -0,0 +1,238 @@
+package chinrem
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"sync"
+)
+
+// Multiplicative orders and discrete logarithms are computed lane by lane, and the lane results are combined with the CRT.
+// They rely on the factorization of the lane orders, expOrd, that is only computed when first needed,
+// since factoring 62 bits numbers is not free.
+// For a discrete logarithm, each lane uses the Pohlig-Hellman reduction, and the baby-step giant-step algorithm
+// in each subgroup of prime order q. Its time and memory cost grows like the square root of the largest such q,
+// so the table is capped to maxBSGS entries : a logarithm that would need a larger table fails with ErrLogTooHard.
+
+var ErrNoLog = fmt.Errorf("no discrete logarithm")
+var ErrLogTooHard = fmt.Errorf("discrete logarithm too hard to compute")
+
+// maxBSGS is the maximum number of entries of the baby-step giant-step table, that is about 32 MiB.
+// It allows subgroups of prime order up to 2^40.
+const maxBSGS = 1 << 20
+
+// groupTables are the lazily computed tables for orders and discrete logarithms.
+// They are shared by pointer, between an engine and its Parallel copies.
+type groupTables struct {
+	once   sync.Once
+	orders [][]factor // factorization of expOrd[i]
+	roots  []int64    // a primitive root for each prime lane, or 0 for composite lanes
+}
+
+// initGroup sets up the lazy group tables.
+func (e *CREngine) initGroup() {
+	e.group = new(groupTables)
+}
+
+// groupTables returns the group tables, computing them if needed.
+func (e *CREngine) groupTables() *groupTables {
+	gt := e.group
+	gt.once.Do(func() {
+		gt.orders = make([][]factor, e.size)
+		gt.roots = make([]int64, e.size)
+		for i, p := range e.primes {
+			gt.orders[i] = factorize(e.expOrd[i])
+			if len(e.factors[i]) == 1 && e.factors[i][0].k == 1 {
+				gt.roots[i] = primitiveRoot(p, gt.orders[i])
+			}
+		}
+	})
+	return gt
+}
+
+// primitiveRoot returns the smallest generator of the multiplicative group modulo the prime p,
+// given the factorization of p-1.
+func primitiveRoot(p int64, f []factor) int64 {
+	for g := int64(1); ; g++ {
+		if g%p == 0 {
+			continue
+		}
+		ok := true
+		for _, q := range f {
+			if expi(g, (p-1)/q.p, p) == 1 {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return g
+		}
+	}
+}
+
+// PrimitiveRoots returns a generator of the multiplicative group, for each prime of the base.
+// They are computed on first use, then cached.
+// It returns ErrNotPrimeBase if the engine moduli are not all prime.
+func (e *CREngine) PrimitiveRoots() ([]int64, error) {
+	if !e.IsPrimeBase() {
+		return nil, ErrNotPrimeBase
+	}
+	return append([]int64(nil), e.groupTables().roots...), nil
+}
+
+// laneOrder returns the multiplicative order of the inversible residue r, on lane i.
+func (e *CREngine) laneOrder(i int, r int64, f []factor) int64 {
+	m := e.primes[i]
+	o := e.expOrd[i]
+	for _, q := range f {
+		for o%q.p == 0 && expi(r, o/q.p, m) == 1 {
+			o /= q.p
+		}
+	}
+	return o
+}
+
+// Order returns the multiplicative order of c, ie the smallest positive n such that c^n = 1 modulo Limit.
+// It is the lcm of the orders of each lane, and it divides Lambda.
+// If c is not inversible, it returns ErrNotInversible.
+func (c *CRI) Order() (*big.Int, error) {
+	gt := c.e.groupTables()
+	o, g, l := big.NewInt(1), new(big.Int), new(big.Int)
+	for i, m := range c.e.primes {
+		r := modInt64(c.rm[i], m)
+		if d, _, _ := gcd(r, m); d != 1 {
+			return nil, ErrNotInversible
+		}
+		l.SetInt64(c.e.laneOrder(i, r, gt.orders[i]))
+		g.GCD(nil, nil, o, l)
+		o.Mul(o, l.Quo(l, g))
+	}
+	return o, nil
+}
+
+// Log returns the smallest n >= 0 such that base^n = c modulo Limit.
+// It returns ErrNotInversible if base is not inversible, and ErrNoLog if c is not a power of base.
+// It returns ErrNotPrimeBase if the engine moduli are not all prime.
+// It returns ErrLogTooHard if the order of base, on some lane, has a prime factor q larger than 2^40,
+// unless c happens to be in a subgroup of base whose order is not divisible by q.
+func (c *CRI) Log(base *CRI) (*big.Int, error) {
+	e := c.e
+	if !e.IsPrimeBase() {
+		return nil, ErrNotPrimeBase
+	}
+	gt := e.groupTables()
+
+	// n is known modulo mod, after each lane
+	n, mod := big.NewInt(0), big.NewInt(1)
+	x, o, g, t := new(big.Int), new(big.Int), new(big.Int), new(big.Int)
+	for i, p := range e.primes {
+		b, a := modInt64(base.rm[i], p), modInt64(c.rm[i], p)
+		if b == 0 {
+			return nil, ErrNotInversible
+		}
+		if a == 0 {
+			return nil, ErrNoLog
+		}
+		ord := e.laneOrder(i, b, gt.orders[i])
+		xi, err := logLane(a, b, ord, gt.orders[i], p)
+		if err != nil {
+			return nil, err
+		}
+
+		// combine n modulo mod with xi modulo ord, where mod and ord may not be coprime.
+		x.SetInt64(xi)
+		o.SetInt64(ord)
+		g.GCD(nil, nil, mod, o)
+		t.Sub(x, n)
+		if new(big.Int).Mod(t, g).Sign() != 0 {
+			return nil, ErrNoLog
+		}
+		// n += mod * ((xi - n)/g * (mod/g)^-1 modulo ord/g)
+		t.Quo(t, g)
+		og := new(big.Int).Quo(o, g)
+		mg := new(big.Int).Quo(mod, g)
+		if og.Cmp(big.NewInt(1)) > 0 {
+			t.Mul(t, mg.ModInverse(mg, og))
+			t.Mod(t, og)
+		} else {
+			t.SetInt64(0)
+		}
+		n.Add(n, t.Mul(t, mod))
+		mod.Mul(mod, og)
+		n.Mod(n, mod)
+	}
+	return n, nil
+}
+
+// logLane returns x such that b^x = a modulo the prime p, with 0 <= x < ord, where ord is the order of b.
+// f is the factorization of p-1. It returns ErrNoLog if there is no solution.
+func logLane(a, b, ord int64, f []factor, p int64) (x int64, err error) {
+	// Pohlig-Hellman : solve modulo each q^k dividing ord, and combine.
+	var mod int64 = 1
+	for _, q := range f {
+		qk, k := int64(1), 0
+		for ord%(qk*q.p) == 0 {
+			qk *= q.p
+			k++
+		}
+		if k == 0 {
+			continue
+		}
+		// solve in the subgroup of order q^k
+		b0, a0 := expi(b, ord/qk, p), expi(a, ord/qk, p)
+		gamma := expi(b0, qk/q.p, p) // order q
+		binv := invmod(b0, p)
+		var xq, qj int64 = 0, 1
+		for j := 0; j < k; j++ {
+			// h = (b0^-xq * a0) ^ (q^(k-1-j))
+			h := mulmod(expi(binv, xq, p), a0, p)
+			h = expi(h, qk/q.p/qj, p)
+			d, err := bsgs(gamma, h, q.p, p)
+			if err != nil {
+				return 0, err
+			}
+			xq += d * qj
+			qj *= q.p
+		}
+		// x = xq modulo qk, and x modulo mod : the moduli are coprime.
+		t := mulmod(modInt64(xq-x, qk), invmod(mod%qk, qk), qk)
+		x += mod * t
+		mod *= qk
+	}
+	if expi(b, x, p) != a {
+		return 0, ErrNoLog
+	}
+	return x, nil
+}
+
+// bsgs returns d, with 0 <= d < n, such that g^d = h modulo the prime p, where g has order n.
+// It returns ErrNoLog if there is no solution, and ErrLogTooHard if the table would exceed maxBSGS entries.
+func bsgs(g, h, n, p int64) (int64, error) {
+	if h == 1 {
+		return 0, nil
+	}
+	m := int64(math.Sqrt(float64(n)))
+	for m*m < n {
+		m++
+	}
+	if m > maxBSGS {
+		return 0, fmt.Errorf("%w : subgroup of order %d, larger than %d", ErrLogTooHard, n, int64(maxBSGS)*maxBSGS)
+	}
+	// baby steps
+	table := make(map[int64]int64, m)
+	for j, gj := int64(0), int64(1); j < m; j++ {
+		if _, ok := table[gj]; !ok {
+			table[gj] = j
+		}
+		gj = mulmod(gj, g, p)
+	}
+	// giant steps
+	step := expi(invmod(g, p), m, p)
+	for i, y := int64(0), h; i < m; i++ {
+		if j, ok := table[y]; ok {
+			return i*m + j, nil
+		}
+		y = mulmod(y, step, p)
+	}
+	return 0, ErrNoLog
+}
@@ -0,0 +1,149 @@
+package chinrem
+
+import (
+	"errors"
+	"math/big"
+	"math/rand"
+	"testing"
+)
+
+// bruteOrder returns the order of x modulo n, or 0 if x is not inversible.
+func bruteOrder(x, n int64) int64 {
+	if g, _, _ := gcd(x, n); g != 1 {
+		return 0
+	}
+	y := x % n
+	for k := int64(1); ; k++ {
+		if y == 1%n {
+			return k
+		}
+		y = y * x % n
+	}
+}
+
+func TestOrder(t *testing.T) {
+	pp, _ := NewCREngineModuli([]int64{8, 9, 25, 7})
+	for _, e := range []*CREngine{NewCREngine(4), pp} {
+		n := e.Limit().Int64()
+		for x := int64(0); x < n; x++ {
+			o, err := e.NewCRIInt64(x).Order()
+			want := bruteOrder(x, n)
+			if want == 0 {
+				if err != ErrNotInversible {
+					t.Fatalf("Order of %d modulo %d should fail, got %v", x, n, o)
+				}
+				continue
+			}
+			if err != nil || o.Int64() != want {
+				t.Fatalf("Order of %d modulo %d : got %v, want %d (%v)", x, n, o, want, err)
+			}
+		}
+	}
+}
+
+func TestPrimitiveRoots(t *testing.T) {
+	e := NewCREngine(30)
+	roots, err := e.PrimitiveRoots()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, g := range roots {
+		p := e.Moduli()[i]
+		if bruteOrder(g, p) != p-1 {
+			t.Fatalf("%d is not a primitive root modulo %d", g, p)
+		}
+	}
+	e = NewCREngine62(3)
+	if roots, err = e.PrimitiveRoots(); err != nil {
+		t.Fatal(err)
+	}
+	for i, g := range roots {
+		p := e.Moduli()[i]
+		if e.laneOrder(i, g, e.groupTables().orders[i]) != p-1 {
+			t.Fatalf("%d is not a primitive root modulo %d", g, p)
+		}
+	}
+	e, _ = NewCREngineModuli([]int64{9, 5, 7})
+	if _, err = e.PrimitiveRoots(); err != ErrNotPrimeBase {
+		t.Fatal("PrimitiveRoots should fail on a composite base", err)
+	}
+}
+
+func TestLogSmall(t *testing.T) {
+	e := NewCREngine(4)
+	n := e.Limit().Int64()
+	for _, b := range []int64{1, 11, 13, 17, 209} {
+		logs := make(map[int64]int64)
+		for k, y := int64(0), int64(1); k < n; k++ {
+			if _, ok := logs[y]; !ok {
+				logs[y] = k
+			}
+			y = y * b % n
+		}
+		for x := int64(0); x < n; x++ {
+			got, err := e.NewCRIInt64(x).Log(e.NewCRIInt64(b))
+			want, ok := logs[x]
+			if !ok {
+				if err != ErrNoLog {
+					t.Fatalf("Log of %d in base %d should fail, got %v", x, b, got)
+				}
+				continue
+			}
+			if err != nil || got.Int64() != want {
+				t.Fatalf("Log of %d in base %d : got %v, want %d (%v)", x, b, got, want, err)
+			}
+		}
+	}
+	if _, err := e.NewCRIInt64(1).Log(e.NewCRIInt64(2)); err != ErrNotInversible {
+		t.Fatal("Log in a non inversible base should fail", err)
+	}
+}
+
+func TestLog(t *testing.T) {
+	rd := rand.New(rand.NewSource(42))
+	for _, e := range []*CREngine{NewCREngine(30), NewCREngineWidth(3, Primes31)} {
+		for i := 0; i < 50; i++ {
+			b := e.NewCRIRand(rd)
+			o, err := b.Order()
+			if err != nil {
+				continue
+			}
+			n := new(big.Int).Rand(rd, e.Limit())
+			a := e.NewCRI().Exp(b, n)
+			got, err := a.Log(b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := n.Mod(n, o); got.Cmp(want) != 0 {
+				t.Fatalf("Log : got %v, want %v", got, want)
+			}
+		}
+	}
+
+	// a 62 bits prime p
+	e := NewCREngine62(1)
+	f := e.groupTables().orders[0]
+	q := f[len(f)-1].p // p-1 has a prime factor q larger than 2^40
+	if q <= maxBSGS*maxBSGS {
+		t.Fatalf("expected a large factor of p-1, got %v", f)
+	}
+	b := e.NewCRIInt64(e.groupTables().roots[0])
+
+	// powers of b^q avoid the subgroup of order q
+	for i := 0; i < 20; i++ {
+		n := big.NewInt(rd.Int63n(e.primes[0]/q) * q)
+		got, err := e.NewCRI().Exp(b, n).Log(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Cmp(n) != 0 {
+			t.Fatalf("Log : got %v, want %v", got, n)
+		}
+	}
+
+	// other powers need a baby-step giant-step table of sqrt(q) entries
+	n := big.NewInt(rd.Int63n(e.primes[0]-1) | 1)
+	if _, err := e.NewCRI().Exp(b, n).Log(b); !errors.Is(err, ErrLogTooHard) {
+		t.Fatal("expected ErrLogTooHard, got", err)
+	}
+}
@@ -10,13 +10,37 @@ import (
 // It can be safely accessed concurrently, because it is never modified once created.
 type CREngine struct {
 	// The core parameters to compute efficiently modulo limit.
-	size   int     // number of primes to consider
-	primes []int64 // The prime base, int 64 format
+	size    int        // number of primes to consider
+	primes  []int64    // The prime base, int 64 format. They are the moduli, not necessarily primes, for NewCREngineModuli.
+	factors [][]factor // The prime factorization of each modulus
 
 	// The following is less efficient, but is only used for input/output and format conversion.
 	limit    *big.Int   // product of all primes
-	phi      *big.Int   // product of all (prime - 1)
+	phi      *big.Int   // Euler totient of limit, ie product of all (prime - 1) for a prime base
 	coprimes []*big.Int // coprimes [i] is the product of primes[j] for j != i, multiplied by its own inverse modulo prime[i], then modulo limit
+
+	// Mixed-radix conversion tables (see mixedradix.go).
+	mrGroups []mrGroup  // groups of consecutive lanes
+	mrInv    [][]uint64 // mrInv[h][g] is the inverse of the modulus of group h, modulo the modulus of group g, for h < g
+	half     []uint64   // group mixed-radix digits of limit/2, rounded down (see signed.go)
+
+	// Exponent reduction, for each lane (see op.go).
+	expOrd  []int64  // Carmichael lambda of the modulus, a multiple of the order of any inversible residue
+	expTail []int64  // the largest prime exponent of the modulus
+	lambda  *big.Int // Carmichael lambda of limit, the lcm of all expOrd
+
+	// Serialization (see marshal.go).
+	fingerprint uint64 // identifies the base
+
+	// Orders and discrete logarithms, computed on first use (see dlog.go).
+	group *groupTables
+
+	// Extenders used by CloneE, built on first use (see extend.go).
+	extenders *extenderCache
+
+	// Parallel execution (see parallel.go).
+	workers   int // number of goroutines, serial if <= 1
+	threshold int // number of residues below which operations stay serial
 }
 
 // Creates a new CREngine with the specified size.
@@ -25,9 +49,76 @@ func NewCREngine(size int) *CREngine {
 	e := new(CREngine)
 	e.size = size
 	e.initPrimes()
+	e.init()
+	return e
+}
+
+// Creates a new CREngine with the specified size, using the largest primes below 2^62.
+// Each prime carries about 62 bits, so a given Limit needs about 7 times fewer primes than NewCREngine.
+// size should be >= 1, or it will be set to 1.
+func NewCREngine62(size int) *CREngine {
+	return NewCREngineWidth(size, Primes62)
+}
+
+// Creates a new CREngine with the specified size, using primes of the specified width.
+// size should be >= 1, or it will be set to 1 (3 for SmallPrimes).
+func NewCREngineWidth(size int, width PrimeWidth) *CREngine {
+	return newCREnginePrimes(widthPrimes(size, width))
+}
+
+// widthPrimes returns the first size primes of the specified width.
+func widthPrimes(size int, width PrimeWidth) []int64 {
+	next := width.generator()
+	primes := make([]int64, 0, size)
+	for len(primes) < size || len(primes) < width.minSize() {
+		primes = append(primes, next())
+	}
+	return primes
+}
+
+// Creates a new CREngine, with the minimal number of primes of the specified width,
+// such that Limit > 2^bits. Any positive number of the specified bit size is then represented exactly.
+func NewCREngineBits(bits int, width PrimeWidth) *CREngine {
+	if bits < 0 {
+		bits = 0
+	}
+	return NewCREngineAtLeast(new(big.Int).Lsh(big.NewInt(1), uint(bits)), width)
+}
+
+// Creates a new CREngine, with the minimal number of primes of the specified width,
+// such that Limit > bound.
+func NewCREngineAtLeast(bound *big.Int, width PrimeWidth) *CREngine {
+	next := width.generator()
+	primes := make([]int64, 0, bound.BitLen()/width.bits()+1)
+	limit := big.NewInt(1)
+	for limit.Cmp(bound) <= 0 || len(primes) < width.minSize() {
+		p := next()
+		primes = append(primes, p)
+		limit.Mul(limit, big.NewInt(p))
+	}
+	return newCREnginePrimes(primes)
+}
+
+// newCREnginePrimes creates a new CREngine from the provided primes.
+func newCREnginePrimes(primes []int64) *CREngine {
+	e := new(CREngine)
+	e.primes = primes
+	e.size = len(primes)
+	e.init()
+	return e
+}
+
+// init computes all the derived values, once the primes are set.
+func (e *CREngine) init() {
+	e.initFactors()
+	e.initFingerprint()
 	e.initLimit()
 	e.initCoprimes()
-	return e
+	e.initMixedRadix()
+	e.initSigned()
+	e.initExp()
+	e.initGroup()
+	e.initExtenders()
 }
 
 // initPrimes compute the primes according to the size set in the engine.
@@ -71,9 +162,9 @@ func (e *CREngine) initPrimes() {
 func (e *CREngine) initLimit() {
 	e.limit = big.NewInt(1)
 	e.phi = big.NewInt(1)
-	for _, p := range e.primes {
+	for i, p := range e.primes {
 		e.limit.Mul(e.limit, big.NewInt(p))
-		e.phi.Mul(e.phi, big.NewInt(p-1))
+		e.phi.Mul(e.phi, totient(e.factors[i]))
 	}
 }
 
@@ -108,6 +199,7 @@ func (e *CREngine) String() string {
 	fmt.Fprintf(sb, "\t\tSize\t%d\n", e.size)
 	fmt.Fprintf(sb, "\t\tLimit\t%v\n", e.limit)
 	fmt.Fprintf(sb, "\t\tPhi  \t%v\n", e.phi)
+	fmt.Fprintf(sb, "\t\tLambda\t%v\n", e.lambda)
 	fmt.Fprintln(sb, "\t\tPrimes\tCoprimes :")
 	for i, p := range e.primes {
 		fmt.Fprintf(sb, "%d\t%9d\t%v\n", i, p, e.coprimes[i])
@@ -120,8 +212,21 @@ func (e *CREngine) Limit() *big.Int {
 	return e.limit
 }
 
-// Phi is the product of all the (prime - 1) in the base.
-// The Fermat theorem states that, for any non zero number a, a ^ phi = 1, modulo Limit.
+// Lambda is the Carmichael function of Limit, ie the lcm of all the (prime - 1) in a prime base.
+// It is the smallest positive exponent such that, for any number a coprime with Limit, a ^ lambda = 1, modulo Limit.
+// It divides Phi, and is usually much smaller.
+func (e *CREngine) Lambda() *big.Int {
+	return e.lambda
+}
+
+// LaneOrders returns, for each modulus of the base, its Carmichael function, ie the exponent of its multiplicative group.
+// Exponents are reduced modulo these values, lane by lane.
+func (e *CREngine) LaneOrders() []int64 {
+	return append([]int64(nil), e.expOrd...)
+}
+
+// Phi is the Euler totient of Limit, ie the product of all the (prime - 1) in a prime base.
+// The Euler theorem states that, for any number a coprime with Limit, a ^ phi = 1, modulo Limit.
 func (e *CREngine) Phi() *big.Int {
 	return e.phi
 }
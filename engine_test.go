@@ -46,3 +46,34 @@ func (e *CREngine) verifyCoprimes(t *testing.T) {
 		}
 	}
 }
+
+func TestEngineBits(t *testing.T) {
+
+	for _, width := range []PrimeWidth{SmallPrimes, Primes31, Primes62} {
+		for _, bits := range []int{0, 1, 10, 31, 62, 63, 100, 1000} {
+			e := NewCREngineBits(bits, width)
+			bound := new(big.Int).Lsh(big.NewInt(1), uint(bits))
+			if e.Limit().Cmp(bound) <= 0 {
+				t.Fatalf("width %d, bits %d : limit %v is too small", width, bits, e.Limit())
+			}
+			// minimal : dropping the last prime should not be enough.
+			if e.size > width.minSize() {
+				l := new(big.Int).Div(e.Limit(), big.NewInt(e.primes[e.size-1]))
+				if l.Cmp(bound) > 0 {
+					t.Fatalf("width %d, bits %d : %d primes is not minimal", width, bits, e.size)
+				}
+			}
+			e.verifyCoprimes(t)
+		}
+	}
+
+	if fmt.Sprint(NewCREngineWidth(20, SmallPrimes).primes) != fmt.Sprint(NewCREngine(20).primes) {
+		t.Fatal("small primes should match NewCREngine")
+	}
+	e := NewCREngineWidth(4, Primes31)
+	for _, p := range e.primes {
+		if p >= 1<<31 || p < 1<<30 {
+			t.Fatalf("invalid 31 bits prime %d", p)
+		}
+	}
+}
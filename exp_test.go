@@ -46,3 +46,120 @@ func TestExp(t *testing.T) {
 
 	}
 }
+
+func TestExpFamily(t *testing.T) {
+	rd := rand.New(rand.NewSource(42))
+	pp, _ := NewCREngineModuli([]int64{8, 9, 25, 7, 11 * 11 * 11}) // prime powers
+
+	for _, e := range []*CREngine{NewCREngine(10), NewCREngine62(3), pp, NewCREngine(20).Parallel(3, 1)} {
+		for i := 0; i < 300; i++ {
+			a := e.NewCRIRand(rd)
+			if i%10 == 0 {
+				a.SetInt64(0)
+			}
+			if i%10 == 1 { // share factors with the limit
+				a.SetInt64(int64(i) * 30)
+			}
+			ab := a.ToBig()
+
+			var n *big.Int
+			switch i % 4 {
+			case 0: // small
+				n = big.NewInt(rd.Int63n(100) - 50)
+			case 1: // int64
+				n = big.NewInt(rd.Int63() - rd.Int63())
+			default: // very large
+				n = new(big.Int).Lsh(big.NewInt(rd.Int63()), uint(rd.Intn(3000)))
+				if i%3 == 0 {
+					n.Neg(n)
+				}
+			}
+
+			want := new(big.Int).Exp(ab, new(big.Int).Abs(n), e.Limit())
+			if n.Sign() < 0 {
+				if want.ModInverse(want, e.Limit()) == nil {
+					c := a.Clone()
+					func() {
+						defer func() {
+							if r := recover(); r != ErrNotInversible {
+								t.Fatalf("expected ErrNotInversible panic, got %v", r)
+							}
+						}()
+						c.Exp(c, n)
+					}()
+					if !c.Equal(a) { // aliased, and left unchanged by the panic
+						t.Fatalf("Exp modified its operand before panicking : %v, want %v", c, a)
+					}
+					continue
+				}
+			}
+
+			checkBig(t, "Exp", e.NewCRI().Exp(a, n), want)
+			checkBig(t, "Exp aliased", a.Clone().Exp(a.Clone(), n), want)
+			if n.IsInt64() {
+				checkBig(t, "ExpI", e.NewCRI().ExpI(a, n.Int64()), want)
+			}
+			if n.Sign() >= 0 {
+				nn := new(big.Int).Mod(n, e.Limit())
+				want.Exp(ab, nn, e.Limit())
+				checkBig(t, "ExpCRI", e.NewCRI().ExpCRI(a, e.NewCRIBig(nn)), want)
+			}
+		}
+	}
+}
+
+func TestLambda(t *testing.T) {
+	rd := rand.New(rand.NewSource(42))
+	pp, _ := NewCREngineModuli([]int64{8, 9, 25, 7, 11 * 11 * 11})
+
+	for _, e := range []*CREngine{NewCREngine(10), NewCREngine62(3), pp} {
+		l := e.Lambda()
+		if new(big.Int).Mod(e.Phi(), l).Sign() != 0 {
+			t.Fatalf("Lambda %v should divide Phi %v", l, e.Phi())
+		}
+		for i, o := range e.LaneOrders() {
+			if new(big.Int).Mod(l, big.NewInt(o)).Sign() != 0 {
+				t.Fatalf("lane order %d (%d) should divide Lambda %v", i, o, l)
+			}
+		}
+		for i := 0; i < 100; i++ {
+			a := e.NewCRIRand(rd)
+			if i%10 == 0 {
+				a.SetInt64(int64(i) * 30)
+			}
+			if a.Inv(a.Clone()) == nil {
+				a.Inv(a) // back to the original value
+				if one := e.NewCRIInt64(1); !e.NewCRI().Exp(a, l).Equal(one) {
+					t.Fatalf("%v ^ Lambda should be 1", a)
+				}
+			}
+			n := new(big.Int).Lsh(big.NewInt(rd.Int63()), uint(rd.Intn(3000)))
+			if i%7 == 0 {
+				n.SetInt64(rd.Int63n(smallExp))
+			}
+			r := e.ReduceExponent(n)
+			if r.Cmp(new(big.Int).Add(l, big.NewInt(smallExp))) >= 0 {
+				t.Fatalf("reduced exponent %v is too large", r)
+			}
+			if !e.NewCRI().Exp(a, r).Equal(e.NewCRI().Exp(a, n)) {
+				t.Fatalf("reduced exponent %v should give the same result as %v", r, n)
+			}
+		}
+	}
+}
+
+func TestExpZeroAlloc(t *testing.T) {
+	e := NewCREngine(100)
+	rd := rand.New(rand.NewSource(42))
+	a, b, n := e.NewCRIRand(rd), e.NewCRIRand(rd), new(big.Int).Lsh(big.NewInt(12345), 2000)
+
+	if allocs := testing.AllocsPerRun(100, func() { a.Exp(b, n) }); allocs != 0 {
+		t.Fatalf("Exp allocated %v times", allocs)
+	}
+	if allocs := testing.AllocsPerRun(100, func() { a.ExpI(b, 1<<60+12345) }); allocs != 0 {
+		t.Fatalf("ExpI allocated %v times", allocs)
+	}
+	if allocs := testing.AllocsPerRun(100, func() { a.ExpCRI(b, b) }); allocs != 0 {
+		t.Fatalf("ExpCRI allocated %v times", allocs)
+	}
+}
@@ -0,0 +1,181 @@
+package chinrem
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Base extension computes the residues of a value for the moduli of another engine, without big.Int.
+// Moduli shared by both engines are simply copied. For the other target moduli, the value is evaluated
+// from its mixed-radix group digits, with the Horner scheme, modulo the target modulus.
+// Whether the value fits the target Limit is decided by comparing its digits with the digits of the target Limit.
+
+var ErrOverflow = fmt.Errorf("value out of range")
+
+// Extender converts CRIs from a source engine to a target engine.
+// It holds the tables precomputed for that pair of engines, and is safe for concurrent use.
+type Extender struct {
+	src, dst *CREngine
+	lane     []int      // lane[j] is the source lane with the same modulus as target lane j, or -1
+	groupMod [][]uint64 // groupMod[j][g] is the modulus of source group g, modulo target modulus j, for computed lanes
+	bound    []uint64   // source group digits of the target Limit, or nil if every source value fits
+	copyOnly bool       // true if every target lane is copied
+}
+
+// NewExtender creates an Extender from the src engine to the dst engine.
+// The engines can share any number of moduli, and need not have the same size.
+func NewExtender(src, dst *CREngine) *Extender {
+	x := newExtender(src, dst)
+	if dst.limit.Cmp(src.limit) < 0 {
+		x.bound = src.NewCRIBig(dst.limit).groupDigits(make([]uint64, len(src.mrGroups)))
+	}
+	return x
+}
+
+// newExtender creates an Extender without the tables to detect overflows, that can only Reduce.
+func newExtender(src, dst *CREngine) *Extender {
+	x := &Extender{src: src, dst: dst, copyOnly: true}
+	index := make(map[int64]int, src.size)
+	for i, p := range src.primes {
+		index[p] = i
+	}
+	x.lane = make([]int, dst.size)
+	x.groupMod = make([][]uint64, dst.size)
+	for j, m := range dst.primes {
+		if i, ok := index[m]; ok {
+			x.lane[j] = i
+			continue
+		}
+		x.lane[j] = -1
+		x.copyOnly = false
+		x.groupMod[j] = make([]uint64, len(src.mrGroups))
+		for g, gr := range src.mrGroups {
+			x.groupMod[j][g] = gr.m % uint64(m)
+		}
+	}
+	return x
+}
+
+// extenderCache holds the Extenders built by CloneE from an engine, keyed by target fingerprint.
+// It is shared by pointer, between an engine and its Parallel copies.
+type extenderCache struct {
+	mu sync.Mutex
+	m  map[uint64]*Extender
+}
+
+// initExtenders sets up the empty Extender cache.
+func (e *CREngine) initExtenders() {
+	e.extenders = &extenderCache{m: make(map[uint64]*Extender)}
+}
+
+// extender returns the cached Extender from e to dst, without the tables to detect overflows, creating it if needed.
+func (e *CREngine) extender(dst *CREngine) *Extender {
+	ec := e.extenders
+	ec.mu.Lock()
+	defer ec.mu.Unlock()
+	x, ok := ec.m[dst.fingerprint]
+	if !ok || !x.dst.sameBase(dst) {
+		x = newExtender(e, dst)
+		ec.m[dst.fingerprint] = x
+	}
+	return x
+}
+
+// isPrefix is true if the moduli of e are the first moduli of o, in the same order.
+func (e *CREngine) isPrefix(o *CREngine) bool {
+	return e.size <= o.size && (e.sameBase(o) || sameModuli(e.primes, o.primes[:e.size]))
+}
+
+// Extend sets c, from the target engine, to the value of a, from the source engine.
+// It returns ErrOverflow, leaving c unchanged, if a does not fit the target Limit.
+func (x *Extender) Extend(c, a *CRI) error {
+	if len(c.rm) != x.dst.size || len(a.rm) != x.src.size {
+		panic("CRIs should match the Extender engines")
+	}
+	var d []uint64
+	if x.bound != nil || !x.copyOnly {
+		d = a.groupDigits(make([]uint64, len(x.src.mrGroups)))
+		if x.bound != nil && cmpDigits(d, x.bound) >= 0 {
+			return ErrOverflow
+		}
+	}
+	x.extend(c, a, d)
+	return nil
+}
+
+// Reduce sets c, from the target engine, to the value of a, from the source engine, modulo the target Limit.
+// It never fails, and is cheap when all the target moduli belong to the source engine.
+func (x *Extender) Reduce(c, a *CRI) {
+	if len(c.rm) != x.dst.size || len(a.rm) != x.src.size {
+		panic("CRIs should match the Extender engines")
+	}
+	var d []uint64
+	if !x.copyOnly {
+		d = a.groupDigits(make([]uint64, len(x.src.mrGroups)))
+	}
+	x.extend(c, a, d)
+}
+
+// extend computes the target residues, from the source group digits d.
+// c and a can be the same CRI only if the engines have the same base.
+func (x *Extender) extend(c, a *CRI, d []uint64) {
+	rm := c.rm
+	if &c.rm[0] == &a.rm[0] {
+		rm = make([]int64, len(c.rm))
+	}
+	for j, m := range x.dst.primes {
+		if i := x.lane[j]; i >= 0 {
+			rm[j] = modInt64(a.rm[i], m)
+			continue
+		}
+		var y int64
+		gm := x.groupMod[j]
+		for g := len(d) - 1; g >= 0; g-- {
+			y = mulAddMod(y, int64(gm[g]), int64(d[g]%uint64(m)), m)
+		}
+		rm[j] = y
+	}
+	copy(c.rm, rm)
+}
+
+// SetE sets c to the value of a, where a can belong to any engine, and returns nil.
+// It returns ErrOverflow, leaving c unchanged, if a does not fit the Limit of c.
+// When converting many values between the same engines, NewExtender is cheaper.
+func (c *CRI) SetE(a *CRI) error {
+	return NewExtender(a.e, c.e).Extend(c, a)
+}
+
+// WithoutLanes returns a new engine, with the same base as e, except the moduli of the provided lanes.
+// A CRI can be moved to the new engine with CloneE or an Extender, which then only copies residues.
+func (e *CREngine) WithoutLanes(lanes ...int) (*CREngine, error) {
+	drop := make([]bool, e.size)
+	for _, i := range lanes {
+		if i < 0 || i >= e.size {
+			return nil, fmt.Errorf("%w : there is no lane %d", ErrInvalidModulus, i)
+		}
+		drop[i] = true
+	}
+	var moduli []int64
+	for i, m := range e.primes {
+		if !drop[i] {
+			moduli = append(moduli, m)
+		}
+	}
+	return e.derive(moduli)
+}
+
+// WithModuli returns a new engine, whose base is the base of e followed by the provided moduli.
+// The moduli are validated as in NewCREngineModuli.
+func (e *CREngine) WithModuli(moduli ...int64) (*CREngine, error) {
+	return e.derive(append(append([]int64(nil), e.primes...), moduli...))
+}
+
+// derive creates a new engine from the provided moduli, keeping the parallel configuration of e.
+func (e *CREngine) derive(moduli []int64) (*CREngine, error) {
+	ne, err := NewCREngineModuli(moduli)
+	if err != nil {
+		return nil, err
+	}
+	ne.workers, ne.threshold = e.workers, e.threshold
+	return ne, nil
+}
@@ -0,0 +1,82 @@
+package chinrem
+
+import (
+	"errors"
+	"math/big"
+	"math/rand"
+	"testing"
+)
+
+func TestExtender(t *testing.T) {
+	rd := rand.New(rand.NewSource(42))
+	e := NewCREngine(20)
+	dropped, err := e.WithoutLanes(0, 5, 19)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wider, err := e.WithModuli(1<<61-1, 1<<32+1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pp, _ := NewCREngineModuli([]int64{64, 81, 25, 49, 11, 13})
+	engines := []*CREngine{e, dropped, wider, pp, NewCREngine62(2), NewCREngine(5), e.Parallel(2, 1)}
+
+	for _, src := range engines {
+		for _, dst := range engines {
+			x := NewExtender(src, dst)
+			for i := 0; i < 20; i++ {
+				a := src.NewCRIRand(rd)
+				if i%4 == 0 { // small values always fit
+					a.SetInt64(int64(i))
+				}
+				ab := a.ToBig()
+				mod := new(big.Int).Mod(ab, dst.Limit())
+
+				c := dst.NewCRIInt64(7)
+				err := x.Extend(c, a)
+				if ab.Cmp(dst.Limit()) >= 0 {
+					if err != ErrOverflow || c.ToBig().Int64() != 7 {
+						t.Fatalf("Extend %v to %v should overflow, got %v", ab, dst.Limit(), err)
+					}
+				} else {
+					if err != nil {
+						t.Fatal(err)
+					}
+					checkBig(t, "Extend", c, ab)
+				}
+				x.Reduce(c, a)
+				checkBig(t, "Reduce", c, mod)
+				checkBig(t, "CloneE", a.CloneE(dst), mod)
+				if err := dst.NewCRI().SetE(a); (err == nil) != (ab.Cmp(dst.Limit()) < 0) {
+					t.Fatalf("SetE %v to %v : unexpected error %v", ab, dst.Limit(), err)
+				}
+			}
+		}
+	}
+
+	// aliasing, with the same base
+	a := e.NewCRIRand(rd)
+	ab := a.ToBig()
+	if err := NewExtender(e, e.Parallel(2, 1)).Extend(a, a); err != nil {
+		t.Fatal(err)
+	}
+	checkBig(t, "aliased Extend", a, ab)
+
+	// CloneE copies to a prefix base, and caches the Extender otherwise, also for Parallel copies.
+	if !NewCREngine(5).isPrefix(e) || !e.isPrefix(wider) || !e.isPrefix(e.Parallel(2, 1)) || e.isPrefix(dropped) {
+		t.Fatal("unexpected isPrefix results")
+	}
+	x, n := e.extender(wider), len(e.extenders.m)
+	e.NewCRIRand(rd).CloneE(wider)
+	e.Parallel(2, 1).NewCRIRand(rd).CloneE(wider.Parallel(2, 1))
+	if e.extender(wider) != x || len(e.extenders.m) != n {
+		t.Fatal("CloneE should reuse the cached Extender")
+	}
+
+	if _, err := e.WithoutLanes(20); !errors.Is(err, ErrInvalidModulus) {
+		t.Fatal("WithoutLanes should fail for a missing lane", err)
+	}
+	if _, err := e.WithModuli(9); !errors.Is(err, ErrNotCoprime) {
+		t.Fatal("WithModuli should fail for a non coprime modulus", err)
+	}
+}
@@ -0,0 +1,135 @@
+package chinrem
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// A CRI can be parsed from :
+//
+//	an integer, in the provided base, such as "-1234", "ff" or, for base 0, "0xff" ;
+//	a tuple of residues, such as "(1, 2, 3)" or "[1 2 3]", with one residue per modulus ;
+//	the output of String, such as "7 ([1 1 2])", whose integer and residues should match.
+//
+// Integers are taken modulo Limit, as with SetBig, so negative integers use the signed interpretation.
+
+var ErrSyntax = fmt.Errorf("invalid CRI syntax")
+
+// ParseCRI parses s, in the provided base, into a new CRI.
+// The base is used for integers, as in big.Int.SetString. Residues are always decimal.
+func (e *CREngine) ParseCRI(s string, base int) (*CRI, error) {
+	c := e.NewCRI()
+	if err := c.parse(s, base); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// SetString sets c to the value of s, in the provided base, and returns c and a boolean indicating success, as big.Int.SetString.
+// If SetString fails, c is unchanged. See ParseCRI for the accepted syntax.
+func (c *CRI) SetString(s string, base int) (*CRI, bool) {
+	if err := c.parse(s, base); err != nil {
+		return nil, false
+	}
+	return c, true
+}
+
+// parse sets c to the value of s, leaving c unchanged on error.
+func (c *CRI) parse(s string, base int) error {
+	s = strings.TrimSpace(s)
+	num, tuple := s, ""
+	if i := strings.IndexAny(s, "(["); i >= 0 {
+		num, tuple = strings.TrimSpace(s[:i]), s[i:]
+	}
+
+	var rm []int64
+	if tuple != "" {
+		var err error
+		if rm, err = c.e.parseResidues(tuple); err != nil {
+			return err
+		}
+	}
+
+	var v *big.Int
+	if num != "" {
+		var ok bool
+		if v, ok = new(big.Int).SetString(num, base); !ok {
+			return fmt.Errorf("%w : %q is not an integer in base %d", ErrSyntax, num, base)
+		}
+	}
+
+	switch {
+	case v == nil && rm == nil:
+		return fmt.Errorf("%w : empty string", ErrSyntax)
+	case rm == nil:
+		c.SetBig(v)
+	case v == nil:
+		copy(c.rm, rm)
+	default:
+		r := c.e.NewCRISlice(rm)
+		if !r.Equal(c.e.NewCRIBig(v)) {
+			return fmt.Errorf("%w : %v does not match the residues %v", ErrSyntax, v, rm)
+		}
+		c.Set(r)
+	}
+	return nil
+}
+
+// parseResidues parses a tuple of decimal residues, within parentheses or brackets, separated by commas or spaces.
+// The residues are normalized.
+func (e *CREngine) parseResidues(s string) ([]int64, error) {
+	for _, p := range []string{"()", "[]"} { // both, for the "([...])" format of String
+		if strings.HasPrefix(s, p[:1]) && strings.HasSuffix(s, p[1:]) {
+			s = strings.TrimSpace(s[1 : len(s)-1])
+		}
+	}
+	fields := strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ' ' || r == '\t' || r == '\n' })
+	if len(fields) != e.size {
+		return nil, fmt.Errorf("%w : %d residues, but the engine has %d moduli", ErrSyntax, len(fields), e.size)
+	}
+	rm := make([]int64, e.size)
+	for i, f := range fields {
+		r, err := strconv.ParseInt(f, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w : invalid residue %d : %v", ErrSyntax, i, err)
+		}
+		rm[i] = modInt64(r, e.primes[i])
+	}
+	return rm, nil
+}
+
+// Format implements fmt.Formatter.
+//
+//	%d, %x, %X, %o, %O, %b print the value, as big.Int does, with the same flags
+//	%v, %s print the value and the residues, as String
+//	%+v, %r print only the residues, such as [1 2 3], without converting to big.Int
+func (c *CRI) Format(f fmt.State, verb rune) {
+	if c == nil {
+		fmt.Fprint(f, "<nil>")
+		return
+	}
+	switch {
+	case verb == 'd' || verb == 'x' || verb == 'X' || verb == 'o' || verb == 'O' || verb == 'b':
+		c.ToBig().Format(f, verb)
+	case verb == 'r' || (verb == 'v' && f.Flag('+')):
+		fmt.Fprintf(f, strings.Replace(fmt.FormatString(f, 's'), "+", "", 1), c.residues())
+	case verb == 'v' || verb == 's':
+		fmt.Fprintf(f, strings.Replace(fmt.FormatString(f, 's'), "+", "", 1), c.String())
+	default:
+		fmt.Fprintf(f, "%%!%c(*chinrem.CRI=%s)", verb, c.residues())
+	}
+}
+
+// residues formats the normalized residues of c, as [r0 r1 ...].
+func (c *CRI) residues() string {
+	buf := []byte{'['}
+	for i, r := range c.rm {
+		if i > 0 {
+			buf = append(buf, ' ')
+		}
+		buf = strconv.AppendInt(buf, modInt64(r, c.e.primes[i]), 10)
+	}
+	return string(append(buf, ']'))
+}
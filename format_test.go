@@ -0,0 +1,82 @@
+package chinrem
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	rd := rand.New(rand.NewSource(42))
+	for _, e := range []*CREngine{NewCREngine(10), NewCREngine62(3)} {
+		for i := 0; i < 50; i++ {
+			a := e.NewCRIRand(rd)
+			ab := a.ToBig()
+			tuple := fmt.Sprint(a.rm)
+			tuple = "(" + strings.ReplaceAll(tuple[1:len(tuple)-1], " ", ", ") + ")"
+			for _, s := range []struct {
+				s    string
+				base int
+			}{
+				{ab.String(), 10},
+				{ab.Text(16), 16},
+				{"0x" + ab.Text(16), 0},
+				{fmt.Sprintf("%+v", a), 10},
+				{fmt.Sprintf("%r", a), 10},
+				{a.String(), 10},
+				{tuple, 10},
+			} {
+				c, err := e.ParseCRI(s.s, s.base)
+				if err != nil || !c.Equal(a) {
+					t.Fatalf("parsing %q : got %v, want %v (%v)", s.s, c, a, err)
+				}
+			}
+		}
+
+		// negative integers use the signed interpretation
+		if c, ok := e.NewCRI().SetString("-5", 10); !ok || c.ToBigSigned().Int64() != -5 {
+			t.Fatal("SetString(-5) failed")
+		}
+		for _, s := range []string{"", "12a", "(1, 2)", "[1 2 x]", "3 ([1 1 1])", "()"} {
+			c := e.NewCRIInt64(7)
+			if _, err := e.ParseCRI(s, 10); !errors.Is(err, ErrSyntax) {
+				t.Fatalf("parsing %q should fail : %v", s, err)
+			}
+			if _, ok := c.SetString(s, 10); ok || c.ToBig().Int64() != 7 {
+				t.Fatalf("SetString %q should fail, leaving c unchanged", s)
+			}
+		}
+	}
+}
+
+func TestFormat(t *testing.T) {
+	e := NewCREngine(3) // 2, 3, 5
+	a := e.NewCRIInt64(29)
+	for _, tc := range []struct{ format, want string }{
+		{"%d", "29"},
+		{"%5d", "   29"},
+		{"%x", "1d"},
+		{"%#X", "0X1D"},
+		{"%b", "11101"},
+		{"%v", "29 ([1 2 4])"},
+		{"%s", "29 ([1 2 4])"},
+		{"%+v", "[1 2 4]"},
+		{"%r", "[1 2 4]"},
+		{"%9r", "  [1 2 4]"},
+		{"%q", "%!q(*chinrem.CRI=[1 2 4])"},
+	} {
+		if got := fmt.Sprintf(tc.format, a); got != tc.want {
+			t.Fatalf("%s : got %q, want %q", tc.format, got, tc.want)
+		}
+	}
+	var n *CRI
+	if got := fmt.Sprintf("%v", n); got != "<nil>" {
+		t.Fatalf("nil CRI : got %q", got)
+	}
+	if fmt.Sprintf("%d", e.NewCRIBig(big.NewInt(-1))) != "29" {
+		t.Fatal("the d verb should print the value in [0, Limit)")
+	}
+}
@@ -0,0 +1,122 @@
+package chinrem
+
+import (
+	"math"
+	"math/big"
+)
+
+// Magnitude estimations use approx, that evaluates the mixed-radix group digits of a value as a float, from the most significant group.
+// Its relative error is below G * 2^-52, where G is the number of radix groups, ie at most the engine size.
+// When an estimation is too close to call, the exact answer is computed from the residues, without big.Int.
+
+// approxTolerance is a bound for the relative error of approx, with a safety margin.
+func (e *CREngine) approxTolerance() float64 {
+	return float64(len(e.mrGroups)+1) * 0x1p-51
+}
+
+// IsEven is true if the value of c, in [0, Limit), is even.
+// Normalization is not required.
+func (c *CRI) IsEven() bool {
+	for i, m := range c.e.primes {
+		if m%2 == 0 { // c and c modulo m have the same parity
+			return modInt64(c.rm[i], m)%2 == 0
+		}
+	}
+	return c.Rem(2) == 0
+}
+
+// BitLen returns the exact length of the value of c, in [0, Limit), in bits. The bit length of 0 is 0.
+// Normalization is not required.
+func (c *CRI) BitLen() int {
+	f, exp := c.approx()
+	if f == 0 {
+		return 0
+	}
+	// c is about f * 2^exp, with 0.5 <= f < 1, so its bit length is exp, unless c is close to a power of 2.
+	tol := c.e.approxTolerance()
+	switch {
+	case f*(1-tol) >= 0.5 && f*(1+tol) < 1:
+		return exp
+	case f*(1+tol) >= 1: // c is about 2^exp
+		if c.cmpPow2(exp) >= 0 {
+			return exp + 1
+		}
+		return exp
+	default: // c is about 2^(exp-1)
+		if c.cmpPow2(exp-1) >= 0 {
+			return exp
+		}
+		return exp - 1
+	}
+}
+
+// cmpPow2 compares the value of c, in [0, Limit), with 2^k, for k >= 0.
+func (c *CRI) cmpPow2(k int) int {
+	if k >= c.e.limit.BitLen() { // 2^k >= Limit
+		return -1
+	}
+	p := c.e.NewCRIInt64(1)
+	if k > 0 {
+		for i, m := range c.e.primes {
+			p.rm[i] = expi(2, int64(k), m)
+		}
+	}
+	return c.Cmp(p)
+}
+
+// ApproxFloat64 returns the value of c, in [0, Limit), as a float64.
+// The relative error is below (size + 1) * 2^-51, and the result is +Inf if c is too large for a float64.
+// Normalization is not required.
+func (c *CRI) ApproxFloat64() float64 {
+	f, exp := c.approx()
+	return math.Ldexp(f, exp)
+}
+
+// ToBigFloat returns the value of c, in [0, Limit), as a big.Float, with precision prec.
+// The mixed-radix digits are accumulated with 32 guard bits, so the relative error is below 2^-prec,
+// ie the result is one of the two big.Float of precision prec closest to c.
+// Normalization is not required.
+func (c *CRI) ToBigFloat(prec uint) *big.Float {
+	d := c.groupDigits(make([]uint64, len(c.e.mrGroups)))
+	b := new(big.Float).SetPrec(prec + 32)
+	t := new(big.Float).SetPrec(prec + 32)
+	for g := len(d) - 1; g >= 0; g-- {
+		b.Mul(b, t.SetUint64(c.e.mrGroups[g].m))
+		b.Add(b, t.SetUint64(d[g]))
+	}
+	return b.SetPrec(prec)
+}
+
+// Exceeds is true if the value of c, in [0, Limit), is strictly larger than bound.
+// It is usually decided from a float estimation of c, and is always exact.
+// Normalization is not required.
+func (c *CRI) Exceeds(bound *big.Int) bool {
+	if bound.Sign() < 0 {
+		return true
+	}
+	if bound.Cmp(c.e.limit) >= 0 {
+		return false
+	}
+	f, exp := c.approx()
+	if f == 0 {
+		return false
+	}
+	if bound.Sign() == 0 {
+		return true
+	}
+	mant := new(big.Float)
+	bexp := new(big.Float).SetInt(bound).MantExp(mant)
+	bf, _ := mant.Float64()
+
+	// ratio of c to bound, as a float, without overflow
+	r := math.Ldexp(f/bf, exp-bexp)
+	tol := c.e.approxTolerance()
+	switch {
+	case r > 1+2*tol:
+		return true
+	case r < 1-2*tol:
+		return false
+	default:
+		return c.Cmp(c.e.NewCRIBig(bound)) > 0
+	}
+}
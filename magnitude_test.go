@@ -0,0 +1,59 @@
+package chinrem
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+)
+
+func TestMagnitude(t *testing.T) {
+	rd := rand.New(rand.NewSource(42))
+	pp, _ := NewCREngineModuli([]int64{81, 25, 49, 11, 13, 17, 19, 23})
+
+	for _, e := range []*CREngine{NewCREngine(50), NewCREngine62(20), pp} {
+		for i := 0; i < 300; i++ {
+			var ab *big.Int
+			switch i % 5 {
+			case 0: // powers of 2, and their neighbours
+				ab = new(big.Int).Lsh(big.NewInt(1), uint(rd.Intn(e.Limit().BitLen()-1)))
+				ab.Add(ab, big.NewInt(int64(rd.Intn(3)-1)))
+			case 1:
+				ab = big.NewInt(int64(i))
+			default:
+				ab = new(big.Int).Rand(rd, new(big.Int).Rsh(e.Limit(), uint(rd.Intn(e.Limit().BitLen()))))
+			}
+			a := e.NewCRIBig(ab)
+
+			if a.IsEven() != (ab.Bit(0) == 0) {
+				t.Fatalf("IsEven of %v should be %v", ab, ab.Bit(0) == 0)
+			}
+			if a.BitLen() != ab.BitLen() {
+				t.Fatalf("BitLen of %v : got %d, want %d", ab, a.BitLen(), ab.BitLen())
+			}
+
+			want := new(big.Float).SetInt(ab)
+			for _, prec := range []uint{24, 53, 200} {
+				got := a.ToBigFloat(prec)
+				diff := new(big.Float).Sub(got, want)
+				if ab.Sign() != 0 && diff.Abs(diff).Cmp(new(big.Float).SetMantExp(want, -int(prec))) > 0 {
+					t.Fatalf("ToBigFloat(%d) of %v : got %v", prec, ab, got)
+				}
+			}
+			if wf, _ := want.Float64(); wf != 0 {
+				if r := a.ApproxFloat64() / wf; r > 1+1e-12 || r < 1-1e-12 {
+					t.Fatalf("ApproxFloat64 of %v : got %v", ab, a.ApproxFloat64())
+				}
+			}
+
+			for _, d := range []int64{-2, -1, 0, 1, 2} {
+				bound := new(big.Int).Add(ab, big.NewInt(d))
+				if a.Exceeds(bound) != (ab.Cmp(bound) > 0) {
+					t.Fatalf("Exceeds %v for %v should be %v", bound, ab, ab.Cmp(bound) > 0)
+				}
+			}
+			if a.Exceeds(e.Limit()) {
+				t.Fatalf("%v should not exceed Limit", ab)
+			}
+		}
+	}
+}
@@ -0,0 +1,321 @@
+package chinrem
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"strings"
+)
+
+// A CRI is encoded in residue form, together with the fingerprint of its engine,
+// so that it can only be decoded into a CRI of an engine with the same base.
+//
+// The binary encoding is : a version byte, the fingerprint (8 bytes, big endian), then the number of residues and each residue, as uvarints.
+// The text encoding is : the fingerprint in hexadecimal, a colon, then the residues in decimal, separated by commas.
+// The JSON encoding is an object with the fingerprint, in hexadecimal, and the list of residues.
+// The gob encoding is the binary encoding.
+//
+// A CREngine is encoded as the list of its moduli, with a format version.
+// The binary encoding is : a version byte, then the number of moduli and each modulus, as uvarints.
+// The JSON encoding is an object with the format version and the list of moduli.
+
+var ErrMalformed = fmt.Errorf("malformed encoding")
+
+// binaryVersion is the version of the binary encodings.
+const binaryVersion = 1
+
+// jsonVersion is the version of the JSON encoding of engines.
+const jsonVersion = 1
+
+// initFingerprint computes the fingerprint of the base.
+func (e *CREngine) initFingerprint() {
+	e.fingerprint = fingerprint(e.primes)
+}
+
+// fingerprint is the FNV-1a hash of the number of moduli, followed by the moduli.
+func fingerprint(moduli []int64) uint64 {
+	h := fnv.New64a()
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], uint64(len(moduli)))
+	h.Write(buf[:])
+	for _, p := range moduli {
+		binary.BigEndian.PutUint64(buf[:], uint64(p))
+		h.Write(buf[:])
+	}
+	return h.Sum64()
+}
+
+// Fingerprint identifies the base of e : it is a FNV-1a hash of the size and of the moduli.
+// Engines with the same base, such as e and e.Parallel(...), have the same fingerprint.
+func (e *CREngine) Fingerprint() uint64 {
+	return e.fingerprint
+}
+
+// checkFingerprint verifies that an encoded CRI can be decoded into c, and returns the engine to decode into.
+// If c has no engine yet, the engine is looked up in reg.
+func (c *CRI) checkFingerprint(reg *Registry, fp uint64, size int) (*CREngine, error) {
+	e := c.e
+	if e == nil {
+		var ok bool
+		if e, ok = reg.Lookup(fp); !ok {
+			return nil, fmt.Errorf("%w : the CRI to decode into has no engine, and engine %016x is not registered", ErrEngineMismatch, fp)
+		}
+	}
+	if fp != e.fingerprint || size != e.size {
+		return nil, fmt.Errorf("%w : encoded for engine %016x (size %d), decoded into engine %016x (size %d)", ErrEngineMismatch, fp, size, e.fingerprint, e.size)
+	}
+	return e, nil
+}
+
+// setResidues sets c from decoded residues, checking that they are normalized.
+// If c has no engine yet, it is set to e.
+func (c *CRI) setResidues(e *CREngine, rm []int64) error {
+	for i, r := range rm {
+		if r < 0 || r >= e.primes[i] {
+			return fmt.Errorf("%w : residue %d is %d, but modulus is %d", ErrMalformed, i, r, e.primes[i])
+		}
+	}
+	if c.e == nil {
+		*c = *e.NewCRI()
+	}
+	copy(c.rm, rm)
+	return nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (c *CRI) MarshalBinary() ([]byte, error) {
+	buf := make([]byte, 9, 9+binary.MaxVarintLen64*(len(c.rm)+1))
+	buf[0] = binaryVersion
+	binary.BigEndian.PutUint64(buf[1:], c.e.fingerprint)
+	buf = binary.AppendUvarint(buf, uint64(len(c.rm)))
+	for i, r := range c.rm {
+		buf = binary.AppendUvarint(buf, uint64(modInt64(r, c.e.primes[i])))
+	}
+	return buf, nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+// c should already belong to an engine with the same fingerprint as the encoded CRI, or that engine should be registered in DefaultRegistry.
+// c is unchanged on error.
+func (c *CRI) UnmarshalBinary(data []byte) error {
+	return c.unmarshalBinary(DefaultRegistry, data)
+}
+
+// unmarshalBinary is UnmarshalBinary, looking up the engine in reg if c has none.
+func (c *CRI) unmarshalBinary(reg *Registry, data []byte) error {
+	if len(data) < 9 || data[0] != binaryVersion {
+		return fmt.Errorf("%w : unknown binary format", ErrMalformed)
+	}
+	fp := binary.BigEndian.Uint64(data[1:])
+	data = data[9:]
+	size, n := binary.Uvarint(data)
+	if n <= 0 || size > uint64(len(data)) {
+		return fmt.Errorf("%w : invalid size", ErrMalformed)
+	}
+	data = data[n:]
+	e, err := c.checkFingerprint(reg, fp, int(size))
+	if err != nil {
+		return err
+	}
+	rm := make([]int64, size)
+	for i := range rm {
+		r, n := binary.Uvarint(data)
+		if n <= 0 || r > MaxModulus {
+			return fmt.Errorf("%w : invalid residue %d", ErrMalformed, i)
+		}
+		rm[i] = int64(r)
+		data = data[n:]
+	}
+	if len(data) != 0 {
+		return fmt.Errorf("%w : %d trailing bytes", ErrMalformed, len(data))
+	}
+	return c.setResidues(e, rm)
+}
+
+// GobEncode implements gob.GobEncoder, using the binary encoding.
+func (c *CRI) GobEncode() ([]byte, error) {
+	return c.MarshalBinary()
+}
+
+// GobDecode implements gob.GobDecoder, using the binary encoding.
+func (c *CRI) GobDecode(data []byte) error {
+	return c.UnmarshalBinary(data)
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (c *CRI) MarshalText() ([]byte, error) {
+	buf := strconv.AppendUint(nil, c.e.fingerprint, 16)
+	buf = append(buf, ':')
+	for i, r := range c.rm {
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+		buf = strconv.AppendInt(buf, modInt64(r, c.e.primes[i]), 10)
+	}
+	return buf, nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+// c should already belong to an engine with the same fingerprint as the encoded CRI, or that engine should be registered in DefaultRegistry.
+// c is unchanged on error.
+func (c *CRI) UnmarshalText(text []byte) error {
+	return c.unmarshalText(DefaultRegistry, text)
+}
+
+// unmarshalText is UnmarshalText, looking up the engine in reg if c has none.
+func (c *CRI) unmarshalText(reg *Registry, text []byte) error {
+	fps, rms, ok := strings.Cut(string(text), ":")
+	if !ok {
+		return fmt.Errorf("%w : missing fingerprint", ErrMalformed)
+	}
+	fp, err := strconv.ParseUint(fps, 16, 64)
+	if err != nil {
+		return fmt.Errorf("%w : invalid fingerprint : %v", ErrMalformed, err)
+	}
+	fields := strings.Split(rms, ",")
+	e, err := c.checkFingerprint(reg, fp, len(fields))
+	if err != nil {
+		return err
+	}
+	rm := make([]int64, len(fields))
+	for i, f := range fields {
+		if rm[i], err = strconv.ParseInt(f, 10, 64); err != nil {
+			return fmt.Errorf("%w : invalid residue %d : %v", ErrMalformed, i, err)
+		}
+	}
+	return c.setResidues(e, rm)
+}
+
+// jsonCRI is the JSON form of a CRI.
+type jsonCRI struct {
+	Fingerprint string  `json:"fingerprint"`
+	Residues    []int64 `json:"residues"`
+}
+
+// MarshalJSON implements json.Marshaler.
+func (c *CRI) MarshalJSON() ([]byte, error) {
+	j := jsonCRI{Fingerprint: strconv.FormatUint(c.e.fingerprint, 16), Residues: make([]int64, len(c.rm))}
+	for i, r := range c.rm {
+		j.Residues[i] = modInt64(r, c.e.primes[i])
+	}
+	return json.Marshal(j)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+// c should already belong to an engine with the same fingerprint as the encoded CRI, or that engine should be registered in DefaultRegistry.
+// c is unchanged on error.
+func (c *CRI) UnmarshalJSON(data []byte) error {
+	return c.unmarshalJSON(DefaultRegistry, data)
+}
+
+// unmarshalJSON is UnmarshalJSON, looking up the engine in reg if c has none.
+func (c *CRI) unmarshalJSON(reg *Registry, data []byte) error {
+	var j jsonCRI
+	if err := json.Unmarshal(data, &j); err != nil {
+		return fmt.Errorf("%w : %v", ErrMalformed, err)
+	}
+	fp, err := strconv.ParseUint(j.Fingerprint, 16, 64)
+	if err != nil {
+		return fmt.Errorf("%w : invalid fingerprint : %v", ErrMalformed, err)
+	}
+	e, err := c.checkFingerprint(reg, fp, len(j.Residues))
+	if err != nil {
+		return err
+	}
+	return c.setResidues(e, j.Residues)
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler, for the base of e.
+// The parallel configuration is not encoded.
+func (e *CREngine) MarshalBinary() ([]byte, error) {
+	buf := make([]byte, 1, 1+binary.MaxVarintLen64*(e.size+1))
+	buf[0] = binaryVersion
+	buf = binary.AppendUvarint(buf, uint64(e.size))
+	for _, p := range e.primes {
+		buf = binary.AppendUvarint(buf, uint64(p))
+	}
+	return buf, nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler, setting e to a new engine with the decoded base.
+// The moduli are validated as in NewCREngineModuli.
+// To reuse engines already in memory, prefer Registry.UnmarshalEngine.
+func (e *CREngine) UnmarshalBinary(data []byte) error {
+	moduli, err := decodeModuli(data)
+	if err != nil {
+		return err
+	}
+	ne, err := NewCREngineModuli(moduli)
+	if err != nil {
+		return err
+	}
+	*e = *ne
+	return nil
+}
+
+// decodeModuli decodes the moduli from the binary encoding of an engine.
+func decodeModuli(data []byte) ([]int64, error) {
+	if len(data) < 1 || data[0] != binaryVersion {
+		return nil, fmt.Errorf("%w : unknown binary format", ErrMalformed)
+	}
+	data = data[1:]
+	size, n := binary.Uvarint(data)
+	if n <= 0 || size > uint64(len(data)) {
+		return nil, fmt.Errorf("%w : invalid size", ErrMalformed)
+	}
+	data = data[n:]
+	moduli := make([]int64, size)
+	for i := range moduli {
+		m, n := binary.Uvarint(data)
+		if n <= 0 || m > MaxModulus {
+			return nil, fmt.Errorf("%w : invalid modulus %d", ErrMalformed, i)
+		}
+		moduli[i] = int64(m)
+		data = data[n:]
+	}
+	if len(data) != 0 {
+		return nil, fmt.Errorf("%w : %d trailing bytes", ErrMalformed, len(data))
+	}
+	return moduli, nil
+}
+
+// GobEncode implements gob.GobEncoder, using the binary encoding.
+func (e *CREngine) GobEncode() ([]byte, error) {
+	return e.MarshalBinary()
+}
+
+// GobDecode implements gob.GobDecoder, using the binary encoding.
+func (e *CREngine) GobDecode(data []byte) error {
+	return e.UnmarshalBinary(data)
+}
+
+// jsonEngine is the JSON form of a CREngine.
+type jsonEngine struct {
+	Version int     `json:"version"`
+	Moduli  []int64 `json:"moduli"`
+}
+
+// MarshalJSON implements json.Marshaler, for the base of e.
+func (e *CREngine) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonEngine{Version: jsonVersion, Moduli: e.primes})
+}
+
+// UnmarshalJSON implements json.Unmarshaler, setting e to a new engine with the decoded base.
+// The moduli are validated as in NewCREngineModuli, and the format version should match.
+func (e *CREngine) UnmarshalJSON(data []byte) error {
+	var j jsonEngine
+	if err := json.Unmarshal(data, &j); err != nil {
+		return fmt.Errorf("%w : %v", ErrMalformed, err)
+	}
+	if j.Version != jsonVersion {
+		return fmt.Errorf("%w : unknown JSON format version %d", ErrMalformed, j.Version)
+	}
+	ne, err := NewCREngineModuli(j.Moduli)
+	if err != nil {
+		return err
+	}
+	*e = *ne
+	return nil
+}
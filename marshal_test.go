@@ -0,0 +1,94 @@
+package chinrem
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"testing"
+)
+
+func TestMarshal(t *testing.T) {
+	rd := rand.New(rand.NewSource(42))
+	pp, _ := NewCREngineModuli([]int64{64, 81, 25, 49, 11})
+
+	for _, e := range []*CREngine{NewCREngine(30), NewCREngine62(4), pp} {
+		other := NewCREngineWidth(e.size, Primes31) // same size, different base
+		pe := e.Parallel(2, 1)                      // same base
+		if pe.Fingerprint() != e.Fingerprint() || other.Fingerprint() == e.Fingerprint() {
+			t.Fatal("unexpected fingerprints")
+		}
+
+		for i := 0; i < 20; i++ {
+			a := e.NewCRIRand(rd)
+
+			bin, _ := a.MarshalBinary()
+			txt, _ := a.MarshalText()
+			js, err := json.Marshal(a)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var gb bytes.Buffer
+			if err := gob.NewEncoder(&gb).Encode(a); err != nil {
+				t.Fatal(err)
+			}
+
+			decoders := map[string]func(c *CRI) error{
+				"binary": func(c *CRI) error { return c.UnmarshalBinary(bin) },
+				"text":   func(c *CRI) error { return c.UnmarshalText(txt) },
+				"json":   func(c *CRI) error { return json.Unmarshal(js, c) },
+				"gob":    func(c *CRI) error { return gob.NewDecoder(bytes.NewReader(gb.Bytes())).Decode(c) },
+			}
+			for name, dec := range decoders {
+				c := pe.NewCRI()
+				if err := dec(c); err != nil || !c.Equal(a) {
+					t.Fatalf("%s round trip failed : %v -> %v (%v)", name, a, c, err)
+				}
+				c = other.NewCRIInt64(5)
+				if err := dec(c); !errors.Is(err, ErrEngineMismatch) || c.ToBig().Int64() != 5 {
+					t.Fatalf("%s decoding into another engine should fail : %v", name, err)
+				}
+			}
+
+			// decoding without engine looks up the engine in a registry
+			reg := NewRegistry()
+			regDecoders := map[string]func(c *CRI) error{
+				"binary": func(c *CRI) error { return c.unmarshalBinary(reg, bin) },
+				"text":   func(c *CRI) error { return c.unmarshalText(reg, txt) },
+				"json":   func(c *CRI) error { return c.unmarshalJSON(reg, js) },
+			}
+			for name, dec := range regDecoders {
+				if err := dec(new(CRI)); !errors.Is(err, ErrEngineMismatch) {
+					t.Fatalf("%s decoding without a registered engine should fail : %v", name, err)
+				}
+			}
+			reg.Register(e)
+			for name, dec := range regDecoders {
+				c := new(CRI)
+				if err := dec(c); err != nil || c.e != e || !c.Equal(a) {
+					t.Fatalf("%s decoding with a registered engine failed : %v -> %v (%v)", name, a, c, err)
+				}
+			}
+		}
+	}
+
+	e := NewCREngine(3)
+	for _, txt := range []string{"", "zz:1,2,3", "1234", e.fingerprintText() + ":1,2", e.fingerprintText() + ":1,2,x", e.fingerprintText() + ":1,2,5"} {
+		if err := e.NewCRI().UnmarshalText([]byte(txt)); err == nil {
+			t.Fatalf("decoding %q should fail", txt)
+		}
+	}
+	bin, _ := e.NewCRIInt64(7).MarshalBinary()
+	for _, b := range [][]byte{nil, bin[:5], bin[:len(bin)-1], append(bin, 0)} {
+		if err := e.NewCRI().UnmarshalBinary(b); !errors.Is(err, ErrMalformed) {
+			t.Fatalf("decoding %v should fail : %v", b, err)
+		}
+	}
+}
+
+// fingerprintText is the fingerprint, as used in the text encoding.
+func (e *CREngine) fingerprintText() string {
+	txt, _ := e.NewCRI().MarshalText()
+	return string(bytes.Split(txt, []byte(":"))[0])
+}
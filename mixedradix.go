@@ -0,0 +1,180 @@
+package chinrem
+
+import (
+	"math/big"
+	"math/bits"
+)
+
+// The mixed-radix representation of a value x, modulo Limit, is the unique list of digits d[i], with 0 <= d[i] < primes[i], such that
+//
+//	x = d[0] + d[1]*p[0] + d[2]*p[0]*p[1] + ... + d[n-1]*p[0]*...*p[n-2]
+//
+// Digits are computed from the residues with the Garner algorithm, using only 64 bits arithmetic.
+// To save work, consecutive primes are first packed into groups whose product still fits in 31 bits,
+// and the Garner algorithm runs on the group moduli. The digits for each prime are then recovered from the group digits.
+// Primes larger than 31 bits stay alone in their group, and use the slower, overflow-free, mulmod.
+
+// maxGroup is the upper bound for the product of the primes in a radix group.
+// It ensures that (a + m) * b never overflows an uint64, for a, b < m.
+const maxGroup = 1 << 31
+
+// mrGroup is a group of consecutive lanes, processed together by the Garner algorithm.
+type mrGroup struct {
+	lo, hi int      // lanes lo (included) to hi (excluded)
+	m      uint64   // product of primes[lo:hi]
+	mu     uint64   // Barrett constant for m
+	crt    []uint64 // crt[k] is 1 modulo primes[lo+k], 0 modulo the other primes of the group, and is smaller than m
+	big    *big.Int // m, as a big.Int
+}
+
+// initMixedRadix precomputes the tables used by the Garner algorithm.
+func (e *CREngine) initMixedRadix() {
+
+	e.mrGroups = nil
+	for lo := 0; lo < e.size; {
+		g := mrGroup{lo: lo, hi: lo + 1, m: uint64(e.primes[lo])}
+		for g.hi < e.size && g.m < maxGroup && e.primes[g.hi] < maxGroup && g.m*uint64(e.primes[g.hi]) < maxGroup {
+			g.m *= uint64(e.primes[g.hi])
+			g.hi++
+		}
+		g.mu = ^uint64(0) / g.m
+		g.big = new(big.Int).SetUint64(g.m)
+		g.crt = make([]uint64, g.hi-g.lo)
+		for k := range g.crt {
+			p := e.primes[g.lo+k]
+			cp := int64(g.m) / p
+			g.crt[k] = uint64(cp * invmod(cp%p, p))
+		}
+		e.mrGroups = append(e.mrGroups, g)
+		lo = g.hi
+	}
+
+	// mrInv[h][g] is the inverse of the modulus of group h, modulo the modulus of group g, for h < g.
+	e.mrInv = make([][]uint64, len(e.mrGroups))
+	for h, gh := range e.mrGroups {
+		e.mrInv[h] = make([]uint64, len(e.mrGroups))
+		for g := h + 1; g < len(e.mrGroups); g++ {
+			m := int64(e.mrGroups[g].m)
+			e.mrInv[h][g] = uint64(invmod(int64(gh.m)%m, m))
+		}
+	}
+}
+
+// invmod returns the inverse of a modulo m, normalized.
+// a and m should be coprime.
+func invmod(a, m int64) int64 {
+	_, u, _ := gcd(a, m)
+	u = u % m
+	if u < 0 {
+		u += m
+	}
+	return u
+}
+
+// mulAddMod computes (a*b + c) modulo m, without overflow.
+// a and b should be normalized, ie 0 <= a, b < m, and c should be positive or 0.
+func mulAddMod(a, b, c, m int64) int64 {
+	hi, lo := bits.Mul64(uint64(a), uint64(b))
+	lo, carry := bits.Add64(lo, uint64(c), 0)
+	return int64(bits.Rem64(hi+carry, lo, uint64(m)))
+}
+
+// reduce computes a modulo m, using the Barrett reduction, where mu is ^uint64(0)/m.
+func reduce(a, m, mu uint64) uint64 {
+	q, _ := bits.Mul64(a, mu)
+	r := a - q*m
+	for r >= m {
+		r -= m
+	}
+	return r
+}
+
+// groupDigits computes the mixed-radix digits of c, relative to the group moduli, into digits, returning digits.
+// digits should have one entry per group.
+// Normalization is not required.
+func (c *CRI) groupDigits(digits []uint64) []uint64 {
+	e := c.e
+	groups := e.mrGroups
+
+	// Compute the residue of c modulo each group modulus.
+	for g, gr := range groups {
+		var s uint64
+		for k, cp := range gr.crt {
+			p := e.primes[gr.lo+k]
+			r := c.rm[gr.lo+k]
+			if r < 0 || r >= p {
+				r %= p
+				if r < 0 {
+					r += p
+				}
+			}
+			s += reduce(uint64(r)*cp, gr.m, gr.mu)
+		}
+		digits[g] = s % gr.m
+	}
+
+	// Once digits[h] is known, it is removed from all the following groups, that are then divided by the modulus of group h.
+	// The inner loop has no dependency between iterations.
+	for h := range groups {
+		dh, inv := digits[h], e.mrInv[h]
+		for g := h + 1; g < len(groups); g++ {
+			m := groups[g].m
+			if m < maxGroup {
+				digits[g] = reduce((digits[g]+m-dh%m)*inv[g], m, groups[g].mu)
+			} else {
+				digits[g] = uint64(mulmod(int64((digits[g]+m-dh%m)%m), int64(inv[g]), int64(m)))
+			}
+		}
+	}
+	return digits
+}
+
+// mixedRadix computes the mixed-radix digits of c into digits, returning digits.
+// digits should have the size of the engine.
+// Normalization is not required.
+func (c *CRI) mixedRadix(digits []int64) []int64 {
+	gd := c.groupDigits(make([]uint64, len(c.e.mrGroups)))
+	for g, gr := range c.e.mrGroups {
+		d := gd[g]
+		for i := gr.lo; i < gr.hi; i++ {
+			p := uint64(c.e.primes[i])
+			digits[i] = int64(d % p)
+			d /= p
+		}
+	}
+	return digits
+}
+
+// MixedRadix returns the mixed-radix digits of c.
+// The value of c is d[0] + d[1]*p[0] + d[2]*p[0]*p[1] + ..., where p are the primes of the engine.
+// Normalization is not required.
+func (c *CRI) MixedRadix() []int64 {
+	return c.mixedRadix(make([]int64, c.e.size))
+}
+
+// SetMixedRadix sets c from its mixed-radix digits, returning c.
+// Panic if length do not match.
+// c is normalized.
+func (c *CRI) SetMixedRadix(digits []int64) *CRI {
+	if len(digits) != c.e.size {
+		panic("Provided slice should match CREngine size")
+	}
+	for i, pi := range c.e.primes {
+		var y int64
+		for j := len(digits) - 1; j >= 0; j-- {
+			y = mulAddMod(y, c.e.primes[j]%pi, digits[j]%pi, pi)
+		}
+		c.rm[i] = y
+	}
+	return c
+}
+
+// fromGroupDigits evaluates the group mixed-radix digits as a big.Int, using the Horner scheme.
+func (e *CREngine) fromGroupDigits(digits []uint64) *big.Int {
+	b, t, d := new(big.Int), new(big.Int), new(big.Int)
+	for g := len(digits) - 1; g >= 0; g-- {
+		t.Mul(b, e.mrGroups[g].big)
+		b.Add(t, d.SetUint64(digits[g]))
+	}
+	return b
+}
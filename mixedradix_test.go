@@ -0,0 +1,74 @@
+package chinrem
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+)
+
+func TestMixedRadix(t *testing.T) {
+	rd := rand.New(rand.NewSource(42))
+
+	for _, size := range []int{3, 5, 10, 50, 200} {
+		e := NewCREngine(size)
+		for i := 0; i < 100; i++ {
+			a := e.NewCRIRand(rd)
+			d := a.MixedRadix()
+
+			// verify digits are in range, and evaluate them.
+			v, p := big.NewInt(0), big.NewInt(1)
+			for j, dj := range d {
+				if dj < 0 || dj >= e.primes[j] {
+					t.Fatalf("digit %d out of range : %d (prime %d)", j, dj, e.primes[j])
+				}
+				v.Add(v, new(big.Int).Mul(big.NewInt(dj), p))
+				p.Mul(p, big.NewInt(e.primes[j]))
+			}
+
+			if v.Cmp(a.toBigCoprimes()) != 0 {
+				t.Fatalf("mixed radix digits do not match value :\n%v\n%v", v, a.toBigCoprimes())
+			}
+			if a.ToBig().Cmp(v) != 0 {
+				t.Fatalf("ToBig do not match :\n%v\n%v", a.ToBig(), v)
+			}
+			if b := e.NewCRI().SetMixedRadix(d); !b.Equal(a) {
+				t.Fatalf("SetMixedRadix round trip failed :\n%v\n%v", a, b)
+			}
+		}
+	}
+}
+
+func TestToBigSmall(t *testing.T) {
+	e := NewCREngine(10)
+	for i := int64(-100); i < 1000; i++ {
+		want := new(big.Int).Mod(big.NewInt(i), e.Limit())
+		if got := e.NewCRIInt64(i).ToBig(); got.Cmp(want) != 0 {
+			t.Fatalf("ToBig of %d : got %v, want %v", i, got, want)
+		}
+	}
+}
+
+func TestCmp(t *testing.T) {
+	rd := rand.New(rand.NewSource(42))
+
+	for _, size := range []int{3, 10, 100} {
+		e := NewCREngine(size)
+		for i := 0; i < 300; i++ {
+			a, b := e.NewCRIRand(rd), e.NewCRIRand(rd)
+			if i%10 == 0 {
+				b.Set(a) // test equality too
+			}
+			if i%7 == 0 { // test small, close values
+				a.SetInt64(int64(i))
+				b.SetInt64(int64(i + rd.Intn(3) - 1))
+			}
+			want := a.ToBig().Cmp(b.ToBig())
+			if got := a.Cmp(b); got != want {
+				t.Fatalf("Cmp(%v, %v) : got %d, want %d", a, b, got, want)
+			}
+			if got := b.Cmp(a); got != -want {
+				t.Fatalf("Cmp(%v, %v) : got %d, want %d", b, a, got, -want)
+			}
+		}
+	}
+}
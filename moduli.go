@@ -0,0 +1,161 @@
+package chinrem
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+)
+
+var ErrInvalidModulus = fmt.Errorf("invalid modulus")
+var ErrNotCoprime = fmt.Errorf("moduli are not pairwise coprime")
+
+// MaxModulus is the largest modulus accepted by NewCREngineModuli.
+// It ensures that the sum of two residues never overflows an int64.
+const MaxModulus = 1 << 62
+
+// factor is a prime power, p^k.
+type factor struct {
+	p int64
+	k int
+}
+
+// Creates a new CREngine using the provided moduli as base.
+// The moduli can be any integers between 2 and MaxModulus, as long as they are pairwise coprime.
+// This allows for primes, prime powers, or special forms such as 2^k-1, 2^k, 2^k+1.
+// Limit is the product of all moduli, Phi is the Euler totient of Limit.
+func NewCREngineModuli(moduli []int64) (*CREngine, error) {
+	if len(moduli) == 0 {
+		return nil, fmt.Errorf("%w : the base should contain at least one modulus", ErrInvalidModulus)
+	}
+	for i, m := range moduli {
+		if m < 2 || m > MaxModulus {
+			return nil, fmt.Errorf("%w : modulus %d is %d, but it should be between 2 and %d", ErrInvalidModulus, i, m, int64(MaxModulus))
+		}
+		for j := 0; j < i; j++ {
+			if g, _, _ := gcd(moduli[j], m); g != 1 {
+				return nil, fmt.Errorf("%w : moduli %d (%d) and %d (%d) share the factor %d", ErrNotCoprime, j, moduli[j], i, m, g)
+			}
+		}
+	}
+	return newCREnginePrimes(append([]int64(nil), moduli...)), nil
+}
+
+// initFactors computes the prime factorization of each modulus.
+func (e *CREngine) initFactors() {
+	e.factors = make([][]factor, e.size)
+	for i, m := range e.primes {
+		e.factors[i] = factorize(m)
+	}
+}
+
+// IsPrimeBase is true if all the moduli of the base are primes.
+func (e *CREngine) IsPrimeBase() bool {
+	for _, f := range e.factors {
+		if len(f) != 1 || f[0].k != 1 {
+			return false
+		}
+	}
+	return true
+}
+
+// Moduli returns a copy of the moduli of the base.
+func (e *CREngine) Moduli() []int64 {
+	return append([]int64(nil), e.primes...)
+}
+
+// totient computes the Euler totient, from the factorization.
+func totient(f []factor) *big.Int {
+	t := big.NewInt(1)
+	for _, pk := range f {
+		t.Mul(t, big.NewInt(pk.p-1))
+		for j := 1; j < pk.k; j++ {
+			t.Mul(t, big.NewInt(pk.p))
+		}
+	}
+	return t
+}
+
+// carmichael computes the Carmichael function, ie the exponent of the multiplicative group, from the factorization.
+// It is the lcm of p^(k-1)*(p-1) for odd primes, and of 1, 2, 2^(k-2) for 2, 4, 2^k with k >= 3.
+// It always fits in an int64, since it is smaller than the modulus.
+func carmichael(f []factor) int64 {
+	l := int64(1)
+	for _, pk := range f {
+		var lk int64
+		switch {
+		case pk.p == 2 && pk.k >= 3:
+			lk = 1 << (pk.k - 2)
+		default:
+			lk = pk.p - 1
+			for j := 1; j < pk.k; j++ {
+				lk *= pk.p
+			}
+		}
+		g, _, _ := gcd(l, lk)
+		l = l / g * lk
+	}
+	return l
+}
+
+// factorize returns the prime factorization of n, sorted by increasing primes.
+// n should be >= 1.
+func factorize(n int64) []factor {
+	if isPrime(n) {
+		return []factor{{n, 1}}
+	}
+	var primes []int64
+
+	// trial division for small factors
+	for p := int64(2); p < 1000 && p*p <= n; p++ {
+		for n%p == 0 {
+			primes = append(primes, p)
+			n /= p
+		}
+	}
+	// Pollard rho for larger factors
+	stack := []int64{n}
+	for len(stack) > 0 {
+		m := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		switch {
+		case m == 1:
+		case isPrime(m):
+			primes = append(primes, m)
+		default:
+			d := rho(m)
+			stack = append(stack, d, m/d)
+		}
+	}
+
+	sort.Slice(primes, func(i, j int) bool { return primes[i] < primes[j] })
+	var f []factor
+	for _, p := range primes {
+		if len(f) > 0 && f[len(f)-1].p == p {
+			f[len(f)-1].k++
+		} else {
+			f = append(f, factor{p, 1})
+		}
+	}
+	return f
+}
+
+// rho finds a non trivial factor of n, using the Pollard-Brent rho algorithm.
+// n should be odd and composite.
+func rho(n int64) int64 {
+	for c := int64(1); ; c++ {
+		f := func(x int64) int64 { return mulAddMod(x, x, c, n) }
+		x, y, d := int64(2), int64(2), int64(1)
+		for d == 1 {
+			x = f(x)
+			y = f(f(y))
+			diff := x - y
+			if diff < 0 {
+				diff = -diff
+			}
+			d, _, _ = gcd(diff, n)
+		}
+		if d != n {
+			return d
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package chinrem
+
+import (
+	"errors"
+	"math/big"
+	"math/rand"
+	"testing"
+)
+
+func TestFactorize(t *testing.T) {
+	rd := rand.New(rand.NewSource(42))
+	for i := 0; i < 300; i++ {
+		n := int64(i + 1)
+		if i > 100 {
+			n = rd.Int63n(MaxModulus) + 1
+		}
+		f := factorize(n)
+		prod := int64(1)
+		for j, pk := range f {
+			if !isPrime(pk.p) || pk.k < 1 || (j > 0 && f[j-1].p >= pk.p) {
+				t.Fatalf("invalid factorization of %d : %v", n, f)
+			}
+			for k := 0; k < pk.k; k++ {
+				prod *= pk.p
+			}
+		}
+		if prod != n {
+			t.Fatalf("invalid factorization of %d : %v", n, f)
+		}
+	}
+}
+
+func TestTotient(t *testing.T) {
+	for n := int64(1); n < 300; n++ {
+		var phi int64
+		for k := int64(1); k <= n; k++ {
+			if g, _, _ := gcd(k, n); g == 1 {
+				phi++
+			}
+		}
+		if got := totient(factorize(n)).Int64(); got != phi {
+			t.Fatalf("totient of %d : got %d, want %d", n, got, phi)
+		}
+	}
+}
+
+func TestCarmichael(t *testing.T) {
+	for n := int64(2); n < 300; n++ {
+		// lambda is the smallest l > 0 such that a^l = 1 for all a coprime with n.
+		var lambda int64
+		for l := int64(1); lambda == 0; l++ {
+			lambda = l
+			for a := int64(1); a < n; a++ {
+				if g, _, _ := gcd(a, n); g == 1 && expi(a, l, n) != 1 {
+					lambda = 0
+					break
+				}
+			}
+		}
+		if got := carmichael(factorize(n)); got != lambda {
+			t.Fatalf("carmichael of %d : got %d, want %d", n, got, lambda)
+		}
+	}
+}
+
+func TestModuliInvalid(t *testing.T) {
+	for _, m := range [][]int64{nil, {}, {1}, {0, 3}, {-5, 3}, {MaxModulus + 1}} {
+		if _, err := NewCREngineModuli(m); !errors.Is(err, ErrInvalidModulus) {
+			t.Fatalf("%v should be invalid, got %v", m, err)
+		}
+	}
+	for _, m := range [][]int64{{2, 4}, {6, 15}, {7, 7}, {3, 5, 7, 9}} {
+		if _, err := NewCREngineModuli(m); !errors.Is(err, ErrNotCoprime) {
+			t.Fatalf("%v should not be coprime, got %v", m, err)
+		}
+	}
+}
+
+func TestModuli(t *testing.T) {
+	rd := rand.New(rand.NewSource(42))
+
+	for _, m := range [][]int64{
+		{255, 256, 257}, // 2^k-1, 2^k, 2^k+1
+		{1<<31 - 1, 1 << 31, 1<<31 + 1},
+		{1<<61 - 1, 1 << 60, 3 * 5 * 7 * 11}, // large moduli
+		{9, 4, 25, 7, 121},                   // prime powers
+		{2, 3, 5, 7},
+	} {
+		e, err := NewCREngineModuli(m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		e.verifyCoprimes(t)
+
+		limit, phi := big.NewInt(1), big.NewInt(1)
+		for _, mi := range m {
+			limit.Mul(limit, big.NewInt(mi))
+			phi.Mul(phi, totient(factorize(mi)))
+		}
+		if e.Limit().Cmp(limit) != 0 || e.Phi().Cmp(phi) != 0 {
+			t.Fatalf("unexpected limit or phi for %v : %v %v", m, e.Limit(), e.Phi())
+		}
+		if e.IsPrimeBase() != (len(m) == 4) {
+			t.Fatalf("IsPrimeBase of %v should not be %v", m, e.IsPrimeBase())
+		}
+
+		for i := 0; i < 100; i++ {
+			a, b := e.NewCRIRand(rd), e.NewCRIRand(rd)
+			ab, bb := a.ToBig(), b.ToBig()
+			if e.NewCRIBig(ab).Cmp(a) != 0 || a.Cmp(b) != ab.Cmp(bb) {
+				t.Fatalf("conversion or comparison failed for %v", a)
+			}
+			checkBig(t, "Mul", e.NewCRI().Mul(a, b), new(big.Int).Mul(ab, bb))
+			if c := e.NewCRI(); c.Inv(a) == nil {
+				checkBig(t, "Inv", c, new(big.Int).ModInverse(ab, e.Limit()))
+			} else if new(big.Int).ModInverse(ab, e.Limit()) != nil {
+				t.Fatalf("%v should be inversible", ab)
+			}
+			q := e.NewCRI()
+			if q.Quo(a, b) == nil {
+				checkBig(t, "Quo", q.Mul(q, b), ab)
+			}
+			if q.Quo(a.Mul(a, b), b) != nil {
+				t.Fatalf("Quo should succeed for a multiple of b")
+			}
+		}
+	}
+}
@@ -19,8 +19,8 @@ func (c *CRI) Limit() *big.Int {
 	return c.e.Limit()
 }
 
-// Phi is the product of all 'primes[i]-1].
-// It is used to simplify exponentiation, since for any non nul number a, a^phi=1, modulo Limit.
+// Phi is the Euler totient of Limit, the product of all 'primes[i]-1' for a prime base.
+// It is used to simplify exponentiation, since for any number a coprime with Limit, a^phi=1, modulo Limit.
 func (c *CRI) Phi() *big.Int {
 	return c.e.Phi()
 }
@@ -97,9 +97,24 @@ func (c *CRI) SetSlice(value []int64) *CRI {
 	return c
 }
 
-// SameEngine checks if both CRI have same size of engine.
+// SameEngine checks if both CRI have engines with the same base, ie the same moduli, in the same order.
+// Engines sharing a base, such as e and e.Parallel(...), are considered the same.
 func SameEngine(a, b *CRI) bool {
-	return a != nil && b != nil && a.e.size == b.e.size
+	return a != nil && b != nil && a.e != nil && b.e != nil && a.e.sameBase(b.e)
+}
+
+// sameBase is true if e and o have the same moduli.
+func (e *CREngine) sameBase(o *CREngine) bool {
+	switch {
+	case e == o:
+		return true
+	case e.fingerprint != o.fingerprint || e.size != o.size:
+		return false
+	case &e.primes[0] == &o.primes[0]: // shared by Parallel
+		return true
+	default:
+		return sameModuli(e.primes, o.primes)
+	}
 }
 
 // Equal compares.
@@ -119,24 +134,104 @@ func (c *CRI) Equal(d *CRI) bool {
 	return true
 }
 
-// Cmp compares x and y and returns:
+// Cmp compares c and a, as integers in [0, Limit), and returns:
 //
 //	-1 if c < a
 //	 0 if c == a
 //	+1 if c > a
 //
-// The order defined is a total ordering that should match natural order for most small positive values.
-// Normalization is assumed, but not enforced.
-// Different engines size will generate a different ordering.
+// The comparison uses the mixed-radix digits of both values, and never converts to big.Int.
+// Normalization is not required.
+// If the bases differ, the order is given by the engines, as described in cmpEngines, and c and a are never equal.
 func (c *CRI) Cmp(a *CRI) int {
 
-	if !SameEngine(a, c) { // sensible values if not same size, to avoid equality.
-		if c.e.size-a.e.size > 0 {
+	if !SameEngine(a, c) {
+		return c.cmpEngines(a)
+	}
+
+	n := len(c.e.mrGroups)
+	dc, da := c.groupDigits(make([]uint64, n)), a.groupDigits(make([]uint64, n))
+	return cmpDigits(dc, da)
+}
+
+// cmpDigits compares two lists of mixed-radix digits, most significant digits last.
+func cmpDigits(dc, da []uint64) int {
+	for i := len(dc) - 1; i >= 0; i-- {
+		switch {
+		case dc[i] > da[i]:
 			return +1
-		} else {
+		case dc[i] < da[i]:
 			return -1
+		default: // loop if equal ...
 		}
 	}
+	return 0
+}
+
+// cmpEngines provides a total order when c and a do not have the same base, to avoid equality.
+// A nil CRI is the smallest, then a CRI without engine. Then, the larger engine size,
+// then the larger fingerprint, then the larger first differing modulus, is considered larger.
+func (c *CRI) cmpEngines(a *CRI) int {
+	rank := func(x *CRI) int {
+		switch {
+		case x == nil:
+			return 0
+		case x.e == nil:
+			return 1
+		default:
+			return 2
+		}
+	}
+	switch rc, ra := rank(c), rank(a); {
+	case rc > ra:
+		return +1
+	case rc < ra:
+		return -1
+	case rc < 2:
+		return 0
+	default:
+		return c.e.cmpBase(a.e)
+	}
+}
+
+// cmpBase orders the bases of e and o, by size, then fingerprint, then moduli. It returns 0 only for the same base.
+func (e *CREngine) cmpBase(o *CREngine) int {
+	switch {
+	case e.size > o.size:
+		return +1
+	case e.size < o.size:
+		return -1
+	case e.fingerprint > o.fingerprint:
+		return +1
+	case e.fingerprint < o.fingerprint:
+		return -1
+	}
+	for i, p := range e.primes {
+		switch {
+		case p > o.primes[i]:
+			return +1
+		case p < o.primes[i]:
+			return -1
+		}
+	}
+	return 0
+}
+
+// CmpResidues compares x and y and returns:
+//
+//	-1 if c < a
+//	 0 if c == a
+//	+1 if c > a
+//
+// The order defined is a total ordering on the residues, starting from the last prime.
+// It is cheap, but it does NOT match the natural order of the values. Use Cmp for that.
+// Normalization is assumed, but not enforced.
+// Different engines size will generate a different ordering.
+func (c *CRI) CmpResidues(a *CRI) int {
+
+	if !SameEngine(a, c) { // sensible values if not same size, to avoid equality.
+		return c.cmpEngines(a)
+	}
 
 	for i := len(c.rm) - 1; i >= 0; i-- {
 		switch {
@@ -171,7 +266,17 @@ func (c *CRI) Clone() *CRI {
 }
 
 // Get the big.Int representation of c.
+// It is computed from the mixed-radix digits of c.
+// Normalization is assumed.
 func (c *CRI) ToBig() *big.Int {
+	if !c.e.serial(c.e.size) {
+		return c.toBigParallel()
+	}
+	return c.e.fromGroupDigits(c.groupDigits(make([]uint64, len(c.e.mrGroups))))
+}
+
+// toBigCoprimes is the original, slower, big.Int conversion, summing rm[i]*coprimes[i] modulo limit.
+func (c *CRI) toBigCoprimes() *big.Int {
 	b := big.NewInt(0)
 	bb := big.NewInt(0)
 
@@ -197,19 +302,17 @@ func (c *CRI) SetRandom(rd *rand.Rand) *CRI {
 }
 
 // Clone c into another CRI, using the provided new engine, en.
-// If c is smaller than both Limits, then the big.Int representation of c stays the same.
-// Cloning to a larger engine is costly, cloning to a shorter engine is cheap.
+// The result is the value of c modulo the Limit of en, so if c is smaller than both Limits, then the big.Int representation of c stays the same.
+// The engines need not share any modulus. Moduli present in both engines are copied, so dropping lanes is cheap.
+// Use SetE or an Extender to detect values that do not fit en.
+// If en has the same base as c, or the first moduli of that base, the residues are copied, and no normalization occurs.
+// Otherwise, the Extender for that pair of engines is built on first use, and kept with the engine of c.
 func (c *CRI) CloneE(en *CREngine) *CRI {
-	if en.size == c.e.size { // same sized engine, no change.
-		return c.Clone()
-	}
-
-	if en.size < c.e.size { // truncating
-		cc := en.NewCRI()
-		copy(cc.rm, c.rm[:en.size])
+	cc := en.NewCRI()
+	if en.isPrefix(c.e) {
+		copy(cc.rm, c.rm)
 		return cc
 	}
-
-	// extending. Convert to a big as an intermediate value.
-	return en.NewCRIBig(c.ToBig())
+	c.e.extender(en).Reduce(cc, c)
+	return cc
 }
@@ -264,6 +264,64 @@ func TestCmpVisual(t *testing.T) {
 	}
 }
 
+func TestCmpResidues(t *testing.T) {
+	e := NewCREngine(5)
+	rd := rand.New(rand.NewSource(42))
+
+	for i := 0; i < 200; i++ {
+		a, b := e.NewCRIRand(rd), e.NewCRIRand(rd)
+		if i%10 == 0 {
+			b.Set(a)
+		}
+		want := 0
+		for j := len(a.rm) - 1; j >= 0 && want == 0; j-- {
+			switch {
+			case a.rm[j] > b.rm[j]:
+				want = +1
+			case a.rm[j] < b.rm[j]:
+				want = -1
+			}
+		}
+		if got := a.CmpResidues(b); got != want {
+			t.Fatalf("CmpResidues(%v, %v) : got %d, want %d", a, b, got, want)
+		}
+		if got := b.CmpResidues(a); got != -want {
+			t.Fatalf("CmpResidues(%v, %v) : got %d, want %d", b, a, got, -want)
+		}
+	}
+
+	// 11 > 10 as values, but 11 = 0 modulo the last prime
+	if e.NewCRIInt64(11).CmpResidues(e.NewCRIInt64(10)) != -1 {
+		t.Fatal("CmpResidues should not follow the order of the values")
+	}
+}
+
+func TestCmpOtherBase(t *testing.T) {
+	e := NewCREngine(3)
+	o, err := NewCREngineModuli([]int64{7, 11, 13})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// f has the fingerprint of e, but the base of o, as in a fingerprint collision
+	f := new(CREngine)
+	*f = *o
+	f.fingerprint = e.fingerprint
+
+	a, b, c := e.NewCRIInt64(5), o.NewCRIInt64(5), f.NewCRIInt64(5)
+	for _, cmp := range []func(x, y *CRI) int{(*CRI).Cmp, (*CRI).CmpSigned, (*CRI).CmpResidues} {
+		for _, xy := range [][2]*CRI{{a, b}, {a, c}, {b, c}, {a, nil}, {a, new(CRI)}, {new(CRI), nil}} {
+			x, y := xy[0], xy[1]
+			xy, yx := cmp(x, y), cmp(y, x)
+			if xy == 0 || xy != -yx {
+				t.Fatalf("values from different bases : got %d and %d", xy, yx)
+			}
+		}
+		if cmp(nil, nil) != 0 || cmp(new(CRI), new(CRI)) != 0 {
+			t.Fatal("CRIs without engine should be equal")
+		}
+	}
+}
+
 func TestQuoVisual(t *testing.T) {
 	e := NewCREngine(3)
 	var a, b, q *CRI
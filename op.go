@@ -3,6 +3,7 @@ package chinrem
 import (
 	"fmt"
 	"math/big"
+	"math/bits"
 )
 
 // Test is c is zero, modulo Limit.
@@ -25,30 +26,123 @@ func (c *CRI) IsOne() bool {
 	return true
 }
 
-// Minus changes the sign of a, store the result in c, returning c
+// Minus changes the sign of a, store the result in c, returning c.
+// It is the same as Neg.
 func (c *CRI) Minus(a *CRI) *CRI {
-	for i, r := range c.rm {
-		c.rm[i] = (-r) % a.e.primes[i]
+	return c.Neg(a)
+}
+
+// Neg changes the sign of a, store the result in c, returning c.
+// Normalization is assumed, and c is normalized.
+func (c *CRI) Neg(a *CRI) *CRI {
+	for i, p := range c.e.primes {
+		if r := a.rm[i]; r != 0 {
+			c.rm[i] = p - r
+		} else {
+			c.rm[i] = 0
+		}
 	}
 	return c
 }
 
 // Add a+b, storing result in c, returning c.
+// Normalization is assumed, and c is normalized.
 func (c *CRI) Add(a, b *CRI) *CRI {
+	if c.e.serial(c.e.size) {
+		c.addLanes(a, b, 0, c.e.size)
+	} else {
+		c.e.shard(c.e.size, func(lo, hi int) { c.addLanes(a, b, lo, hi) })
+	}
+	return c
+}
+
+// addLanes computes Add, on lanes lo to hi.
+func (c *CRI) addLanes(a, b *CRI, lo, hi int) {
+	for i := lo; i < hi; i++ {
+		p := c.e.primes[i]
+		r := a.rm[i] + b.rm[i]
+		if r >= p {
+			r -= p
+		}
+		c.rm[i] = r
+	}
+}
+
+// Sub a-b, storing result in c, returning c.
+// Normalization is assumed, and c is normalized.
+func (c *CRI) Sub(a, b *CRI) *CRI {
+	for i, p := range c.e.primes {
+		r := a.rm[i] - b.rm[i]
+		if r < 0 {
+			r += p
+		}
+		c.rm[i] = r
+	}
+	return c
+}
+
+// modInt64 returns v modulo p, normalized.
+func modInt64(v, p int64) int64 {
+	r := v % p
+	if r < 0 {
+		r += p
+	}
+	return r
+}
+
+// AddInt64 a+v, storing result in c, returning c.
+// Normalization is assumed, and c is normalized.
+func (c *CRI) AddInt64(a *CRI, v int64) *CRI {
+	for i, p := range c.e.primes {
+		r := a.rm[i] + modInt64(v, p)
+		if r >= p {
+			r -= p
+		}
+		c.rm[i] = r
+	}
+	return c
+}
+
+// SubInt64 a-v, storing result in c, returning c.
+// Normalization is assumed, and c is normalized.
+func (c *CRI) SubInt64(a *CRI, v int64) *CRI {
 	for i, p := range c.e.primes {
-		c.rm[i] = (a.rm[i] + c.rm[i]) % p
+		r := a.rm[i] - modInt64(v, p)
+		if r < 0 {
+			r += p
+		}
+		c.rm[i] = r
+	}
+	return c
+}
+
+// MulInt64 a*v, storing result in c, returning c.
+// Normalization is assumed, and c is normalized.
+func (c *CRI) MulInt64(a *CRI, v int64) *CRI {
+	for i, p := range c.e.primes {
+		c.rm[i] = mulmod(a.rm[i], modInt64(v, p), p)
 	}
 	return c
 }
 
 // Mul a*b, storing result in c, returning c.
+// Normalization is assumed, and c is normalized.
 func (c *CRI) Mul(a, b *CRI) *CRI {
-	for i, p := range c.e.primes {
-		c.rm[i] = (a.rm[i] * b.rm[i]) % p
+	if c.e.serial(c.e.size) {
+		c.mulLanes(a, b, 0, c.e.size)
+	} else {
+		c.e.shard(c.e.size, func(lo, hi int) { c.mulLanes(a, b, lo, hi) })
 	}
 	return c
 }
 
+// mulLanes computes Mul, on lanes lo to hi.
+func (c *CRI) mulLanes(a, b *CRI, lo, hi int) {
+	for i := lo; i < hi; i++ {
+		c.rm[i] = mulmod(a.rm[i], b.rm[i], c.e.primes[i])
+	}
+}
+
 // utility that returns g as the gcd of a and b, and u,v such that au + bv = g, using Euclid algorithm.
 // By convention, gcd(0,0) = 0 and gcd(0,a) = a
 func gcd(a, b int64) (g, u, v int64) {
@@ -73,10 +167,17 @@ var ErrNotInversible = fmt.Errorf("not inversible")
 // Compute the inverse of a modulo Limit, store result in c.
 // If no inverse can be found, return ErrNotInversible.
 func (c *CRI) Inv(a *CRI) error {
+	if c.e.serial(c.e.size) {
+		return c.invLanes(a, 0, c.e.size)
+	}
+	return c.e.shardErr(c.e.size, func(lo, hi int) error { return c.invLanes(a, lo, hi) })
+}
 
-	for i, r := range a.rm {
+// invLanes computes Inv, on lanes lo to hi.
+func (c *CRI) invLanes(a *CRI, lo, hi int) error {
+	for i := lo; i < hi; i++ {
 		p := a.e.primes[i]
-		g, u, _ := gcd(r, p)
+		g, u, _ := gcd(a.rm[i], p)
 		if g != 1 {
 			return ErrNotInversible
 		}
@@ -94,6 +195,7 @@ var ErrNotDivisible = fmt.Errorf("is not divisible")
 
 // Quo computes quotient q of a/b modulo limit, such that a = bq modulo limit, and stores result in c, returns error if not divisible.
 // In general, this is very different from the usual integer quotient a/b.
+// The quotient may not be unique : see QuoFree, QuoCount, QuoAll and QuoMin for the whole set of solutions.
 func (c *CRI) Quo(a, b *CRI) error {
 
 	bIsZero := true
@@ -119,9 +221,14 @@ func (c *CRI) Quo(a, b *CRI) error {
 				bIsZero = false
 				if bi == ai {
 					c.rm[i] = 1
+				} else if g, _, _ := gcd(bi, pi); g == 1 {
+					c.rm[i] = mulmod(invmod(bi, pi), ai, pi)
 				} else {
-					_, r, _ := gcd(bi, pi)
-					c.rm[i] = (r * ai) % pi
+					// composite modulus, bi is not inversible : divide everything by g, if possible.
+					if ai%g != 0 {
+						return ErrNotDivisible
+					}
+					c.rm[i] = mulmod(invmod(bi/g, pi/g), ai/g, pi/g)
 				}
 			}
 		}
@@ -139,6 +246,9 @@ func expi(a, b, m int64) (r int64) {
 		panic(fmt.Sprintf("operation not defined  : %v^%v[%v]", a, b, m))
 	}
 	a = a % m
+	if a < 0 {
+		a += m
+	}
 
 	if a == 0 || a == 1 || b == 1 {
 		return a
@@ -148,10 +258,10 @@ func expi(a, b, m int64) (r int64) {
 	for b > 0 {
 		if b%2 == 1 {
 			// b is odd, multiply result
-			r = (r * a) % m
+			r = mulmod(r, a, m)
 		}
 		b = b >> 1
-		a = (a * a) % m
+		a = mulmod(a, a, m)
 	}
 	return r
 }
@@ -188,15 +298,6 @@ func expb(a int64, n *big.Int, buffer *big.Int, m int64) (r int64) {
 
 */
 
-// ExpI computes a^n modulo limit, where the exponent is a positive int64, stores the result in c and returns it.
-func (c *CRI) ExpI(a *CRI, n int64) *CRI {
-
-	for i, ai := range a.rm {
-		c.rm[i] = expi(ai, n, a.e.primes[i])
-	}
-	return c
-}
-
 /*
 // Deprecated
 func (c *CRI) ExpB(a *CRI, n *big.Int) *CRI {
@@ -218,38 +319,200 @@ func (c *CRI) ExpB(a *CRI, n *big.Int) *CRI {
 }
 */
 
-// Exp computes a^n modulo limit, where the exponent is a positive int64, stores the result in c and returns it.
-func (c *CRI) Exp(a *CRI, n *big.Int) *CRI {
-	switch n.Sign() {
-	case 0:
-		copy(c.rm, a.rm)
-		return c
-	case -1:
-		panic("negative exponents are not implemented")
-	default:
+// Exponentiation is done lane by lane. For each lane, with modulus m, the exponent n is first reduced to a small exponent,
+// that gives the same result for any residue : n modulo expOrd[i], plus a multiple of expOrd[i] to stay above expTail[i].
+// expOrd[i] is the Carmichael lambda of m, a multiple of the order of any inversible residue,
+// and expTail[i] is the largest exponent of any prime in m, so that non inversible residues are also correctly handled.
+
+// initExp precomputes the exponent reduction values for each lane, and the Carmichael lambda of the limit.
+func (e *CREngine) initExp() {
+	e.expOrd = make([]int64, e.size)
+	e.expTail = make([]int64, e.size)
+	e.lambda = big.NewInt(1)
+	g, l := new(big.Int), new(big.Int)
+	for i, f := range e.factors {
+		e.expOrd[i] = carmichael(f)
+		for _, pk := range f {
+			if int64(pk.k) > e.expTail[i] {
+				e.expTail[i] = int64(pk.k)
+			}
+		}
+		// lambda = lcm(lambda, expOrd[i])
+		l.SetInt64(e.expOrd[i])
+		g.GCD(nil, nil, e.lambda, l)
+		e.lambda.Mul(e.lambda, l.Quo(l, g))
+	}
+}
+
+// ReduceExponent returns a non negative exponent, smaller than Lambda + 64, such that a^n = a^ReduceExponent(n), for any a, modulo Limit.
+// When the same enormous exponent is used repeatedly, reducing it once makes each exponentiation proportional to the bit length of Lambda.
+// n should be positive or 0.
+func (e *CREngine) ReduceExponent(n *big.Int) *big.Int {
+	if n.Sign() < 0 {
+		panic("ReduceExponent requires a positive exponent")
+	}
+	r := new(big.Int).Set(n)
+	if r.Cmp(big.NewInt(smallExp)) < 0 {
+		return r
+	}
+	r.Mod(r, e.lambda)
+	for r.Cmp(big.NewInt(smallExp)) < 0 { // stay above any expTail
+		r.Add(r, e.lambda)
+	}
+	return r
+}
 
-		nn := big.NewInt(0).Mod(n, a.Phi())
-		aa := a.Clone()
+// smallExp is the bound below which exponents are used without reduction.
+// It is larger than any expTail.
+const smallExp = 64
 
-		for i := range c.rm {
-			c.rm[i] = 1
+// laneExp returns the reduced exponent for lane i, given r, the exponent modulo expOrd[i].
+// The exponent should be at least smallExp.
+func (e *CREngine) laneExp(i int, r int64) int64 {
+	for r < e.expTail[i] {
+		r += e.expOrd[i]
+	}
+	return r
+}
+
+// bigModInt64 computes n modulo m, ignoring the sign of n, without allocating.
+func bigModInt64(n *big.Int, m int64) int64 {
+	var r uint64
+	um := uint64(m)
+	words := n.Bits()
+	for w := len(words) - 1; w >= 0; w-- {
+		if bits.UintSize == 64 {
+			r = bits.Rem64(r, uint64(words[w]), um)
+		} else {
+			r = bits.Rem64(r>>32, r<<32|uint64(words[w]), um)
 		}
-		for nn.Sign() > 0 {
+	}
+	return int64(r)
+}
 
-			if nn.Bit(0) == 1 {
-				// n is odd, multiply result
-				c.Mul(c, aa)
-			}
-			nn.Rsh(nn, 1)
-			aa.Mul(aa, aa)
+// expLane computes a^n on lane i, where n is the reduced exponent.
+func (c *CRI) expLane(a *CRI, i int, n int64) {
+	m := c.e.primes[i]
+	if n == 0 {
+		c.rm[i] = 1 % m
+		return
+	}
+	c.rm[i] = expi(a.rm[i], n, m)
+}
+
+// invForExp sets c to the inverse of a, and returns c, to compute negative exponents.
+// It panics with ErrNotInversible if there is no inverse, before modifying c.
+func (c *CRI) invForExp(a *CRI) *CRI {
+	for i, r := range a.rm {
+		if !a.e.isUnit(modInt64(r, a.e.primes[i]), i) {
+			panic(ErrNotInversible)
 		}
-		return c
-		/*
-			// this alternative, although simpler, is less efficient (x2) ...
-			aa := a.ToBig()
-			c.SetBig(aa.Exp(aa, n, a.Limit()))
-			return c
-		*/
 	}
+	c.Inv(a)
+	return c
+}
+
+// ExpI computes a^n modulo limit, where the exponent is an int64, stores the result in c and returns it.
+// a^0 is 1, for any a.
+// For a negative n, the result is the inverse of a^-n. It panics with ErrNotInversible if a has no inverse.
+// Normalization is assumed, and c is normalized. No memory is allocated.
+func (c *CRI) ExpI(a *CRI, n int64) *CRI {
+	if n < 0 {
+		return c.expI(c.invForExp(a), -uint64(n))
+	}
+	return c.expI(a, uint64(n))
+}
 
+// expI computes a^n, dispatching the lanes.
+func (c *CRI) expI(a *CRI, n uint64) *CRI {
+	if c.e.serial(c.e.size) {
+		c.expILanes(a, n, 0, c.e.size)
+	} else {
+		c.e.shard(c.e.size, func(lo, hi int) { c.expILanes(a, n, lo, hi) })
+	}
+	return c
+}
+
+// expILanes computes expI, on lanes lo to hi.
+func (c *CRI) expILanes(a *CRI, n uint64, lo, hi int) {
+	for i := lo; i < hi; i++ {
+		r := int64(n)
+		if n >= smallExp {
+			r = c.e.laneExp(i, int64(n%uint64(c.e.expOrd[i])))
+		}
+		c.expLane(a, i, r)
+	}
+}
+
+// Exp computes a^n modulo limit, where the exponent is a big.Int, stores the result in c and returns it.
+// a^0 is 1, for any a.
+// For a negative n, the result is the inverse of a^-n. It panics with ErrNotInversible if a has no inverse.
+// Normalization is assumed, and c is normalized. No memory is allocated.
+func (c *CRI) Exp(a *CRI, n *big.Int) *CRI {
+	if n.IsInt64() {
+		return c.ExpI(a, n.Int64())
+	}
+	if n.Sign() < 0 {
+		a = c.invForExp(a)
+	}
+	if c.e.serial(c.e.size) {
+		c.expLanes(a, n, 0, c.e.size)
+	} else {
+		c.e.shard(c.e.size, func(lo, hi int) { c.expLanes(a, n, lo, hi) })
+	}
+	return c
+}
+
+// expLanes computes Exp, on lanes lo to hi, for a large n.
+func (c *CRI) expLanes(a *CRI, n *big.Int, lo, hi int) {
+	for i := lo; i < hi; i++ {
+		c.expLane(a, i, c.e.laneExp(i, bigModInt64(n, c.e.expOrd[i])))
+	}
+}
+
+// ExpCRI computes a^n modulo limit, where the exponent is the value of the CRI n, in [0, Limit).
+// It stores the result in c and returns it.
+// a^0 is 1, for any a.
+// Normalization is assumed, and c is normalized. No memory is allocated, for engines up to 64 groups of lanes.
+func (c *CRI) ExpCRI(a, n *CRI) *CRI {
+	var buf [64]uint64
+	var d []uint64
+	if len(c.e.mrGroups) <= len(buf) {
+		d = buf[:len(c.e.mrGroups)]
+	} else {
+		d = make([]uint64, len(c.e.mrGroups))
+	}
+	n.groupDigits(d)
+
+	small := d[0] < smallExp
+	for _, dg := range d[1:] {
+		small = small && dg == 0
+	}
+	if small {
+		return c.expI(a, d[0])
+	}
+	if c.e.serial(c.e.size) {
+		c.expDigitsLanes(a, d, 0, c.e.size)
+	} else {
+		dd := append([]uint64(nil), d...)
+		c.e.shard(c.e.size, func(lo, hi int) { c.expDigitsLanes(a, dd, lo, hi) })
+	}
+	return c
+}
+
+// expDigitsLanes computes ExpCRI, on lanes lo to hi, where d are the group digits of a large exponent.
+func (c *CRI) expDigitsLanes(a *CRI, d []uint64, lo, hi int) {
+	for i := lo; i < hi; i++ {
+		c.expLane(a, i, c.e.laneExp(i, c.e.groupDigitsMod(d, c.e.expOrd[i])))
+	}
+}
+
+// groupDigitsMod evaluates the group mixed-radix digits modulo m, using the Horner scheme.
+func (e *CREngine) groupDigitsMod(d []uint64, m int64) int64 {
+	var r int64
+	for g := len(d) - 1; g >= 0; g-- {
+		gr := e.mrGroups[g]
+		r = mulAddMod(r, int64(gr.m%uint64(m)), int64(d[g]%uint64(m)), m)
+	}
+	return r
 }
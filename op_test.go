@@ -0,0 +1,88 @@
+package chinrem
+
+import (
+	"math"
+	"math/big"
+	"math/rand"
+	"testing"
+)
+
+// checkNormalized fails if any residue of c is out of [0, p).
+func checkNormalized(t *testing.T, c *CRI) {
+	t.Helper()
+	for i, r := range c.rm {
+		if r < 0 || r >= c.e.primes[i] {
+			t.Fatalf("residue %d is not normalized : %d (prime %d)", i, r, c.e.primes[i])
+		}
+	}
+}
+
+// checkBig fails if c does not match want, modulo Limit.
+func checkBig(t *testing.T, op string, c *CRI, want *big.Int) {
+	t.Helper()
+	checkNormalized(t, c)
+	want = new(big.Int).Mod(want, c.Limit())
+	if got := c.ToBig(); got.Cmp(want) != 0 {
+		t.Fatalf("%s : got %v, want %v", op, got, want)
+	}
+}
+
+func TestOpDifferential(t *testing.T) {
+	rd := rand.New(rand.NewSource(42))
+	int64s := []int64{0, 1, -1, 2, -2, 300, -300, math.MaxInt64, math.MinInt64, math.MaxInt64 - 1, math.MinInt64 + 1}
+
+	for _, e := range []*CREngine{NewCREngine(3), NewCREngine(10), NewCREngine(60), NewCREngine62(1), NewCREngine62(5)} {
+
+		for i := 0; i < 200; i++ {
+			a, b := e.NewCRIRand(rd), e.NewCRIRand(rd)
+			if i%5 == 0 {
+				a.SetInt64(rd.Int63n(10))
+			}
+			if i%7 == 0 {
+				b.SetInt64(-1)
+			}
+			ab, bb := a.ToBig(), b.ToBig()
+			v := rd.Int63() - rd.Int63()
+			if i < len(int64s) {
+				v = int64s[i]
+			}
+			vb := big.NewInt(v)
+
+			checkBig(t, "Add", e.NewCRI().Add(a, b), new(big.Int).Add(ab, bb))
+			checkBig(t, "Sub", e.NewCRI().Sub(a, b), new(big.Int).Sub(ab, bb))
+			checkBig(t, "Mul", e.NewCRI().Mul(a, b), new(big.Int).Mul(ab, bb))
+			checkBig(t, "Neg", e.NewCRI().Neg(a), new(big.Int).Neg(ab))
+			checkBig(t, "Minus", e.NewCRI().Minus(a), new(big.Int).Neg(ab))
+			checkBig(t, "AddInt64", e.NewCRI().AddInt64(a, v), new(big.Int).Add(ab, vb))
+			checkBig(t, "SubInt64", e.NewCRI().SubInt64(a, v), new(big.Int).Sub(ab, vb))
+			checkBig(t, "MulInt64", e.NewCRI().MulInt64(a, v), new(big.Int).Mul(ab, vb))
+
+			// aliasing : result stored in an operand, or same operand twice.
+			checkBig(t, "Add c=a", a.Clone().Add(a.Clone(), b), new(big.Int).Add(ab, bb))
+			c := a.Clone()
+			checkBig(t, "Add c=a", c.Add(c, b), new(big.Int).Add(ab, bb))
+			c = b.Clone()
+			checkBig(t, "Add c=b", c.Add(a, c), new(big.Int).Add(ab, bb))
+			c = a.Clone()
+			checkBig(t, "Add c=a=b", c.Add(c, c), new(big.Int).Add(ab, ab))
+			c = a.Clone()
+			checkBig(t, "Sub c=a", c.Sub(c, b), new(big.Int).Sub(ab, bb))
+			c = b.Clone()
+			checkBig(t, "Sub c=b", c.Sub(a, c), new(big.Int).Sub(ab, bb))
+			c = a.Clone()
+			checkBig(t, "Sub c=a=b", c.Sub(c, c), big.NewInt(0))
+			c = a.Clone()
+			checkBig(t, "Mul c=a=b", c.Mul(c, c), new(big.Int).Mul(ab, ab))
+			c = b.Clone()
+			checkBig(t, "Mul c=b", c.Mul(a, c), new(big.Int).Mul(ab, bb))
+			c = a.Clone()
+			checkBig(t, "Neg c=a", c.Neg(c), new(big.Int).Neg(ab))
+			c = a.Clone()
+			checkBig(t, "AddInt64 c=a", c.AddInt64(c, v), new(big.Int).Add(ab, vb))
+			c = a.Clone()
+			checkBig(t, "SubInt64 c=a", c.SubInt64(c, v), new(big.Int).Sub(ab, vb))
+			c = a.Clone()
+			checkBig(t, "MulInt64 c=a", c.MulInt64(c, v), new(big.Int).Mul(ab, vb))
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package chinrem
+
+import (
+	"math/big"
+	"runtime"
+	"sync"
+)
+
+// DefaultThreshold is the default number of residues below which operations stay serial, on a parallel engine.
+// It was chosen from BenchmarkParallel.
+const DefaultThreshold = 4096
+
+// Parallel returns a new engine, sharing the same base as e, whose operations shard the residue lanes across workers goroutines.
+// Operations involving fewer than threshold residues stay serial.
+// If workers <= 0, runtime.GOMAXPROCS(0) is used. If threshold <= 0, DefaultThreshold is used.
+// CRIs from e and from the returned engine can be mixed freely.
+// The engine e itself is not modified.
+func (e *CREngine) Parallel(workers, threshold int) *CREngine {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if threshold <= 0 {
+		threshold = DefaultThreshold
+	}
+	pe := new(CREngine)
+	*pe = *e
+	pe.workers, pe.threshold = workers, threshold
+	return pe
+}
+
+// Workers is the number of goroutines used for parallel operations, 1 for a serial engine.
+func (e *CREngine) Workers() int {
+	if e.workers <= 1 {
+		return 1
+	}
+	return e.workers
+}
+
+// serial is true if processing n residues should stay serial.
+func (e *CREngine) serial(n int) bool {
+	return e.workers <= 1 || n < e.threshold
+}
+
+// shard splits [0, n) into consecutive ranges, and runs f on each range, using up to e.workers goroutines.
+// It returns when all ranges have been processed.
+func (e *CREngine) shard(n int, f func(lo, hi int)) {
+	w := e.workers
+	if w > n {
+		w = n
+	}
+	if w <= 1 {
+		f(0, n)
+		return
+	}
+	chunk := (n + w - 1) / w
+	var wg sync.WaitGroup
+	for lo := chunk; lo < n; lo += chunk {
+		hi := lo + chunk
+		if hi > n {
+			hi = n
+		}
+		wg.Add(1)
+		go func(lo, hi int) {
+			defer wg.Done()
+			f(lo, hi)
+		}(lo, hi)
+	}
+	f(0, chunk) // the calling goroutine processes the first range
+	wg.Wait()
+}
+
+// shardErr is the same as shard, for a function that can fail. It returns one of the errors, if any.
+func (e *CREngine) shardErr(n int, f func(lo, hi int) error) error {
+	var mu sync.Mutex
+	var err error
+	e.shard(n, func(lo, hi int) {
+		if er := f(lo, hi); er != nil {
+			mu.Lock()
+			err = er
+			mu.Unlock()
+		}
+	})
+	return err
+}
+
+// toBigParallel computes the big.Int representation of c, summing rm[i]*coprimes[i] on each shard of lanes.
+func (c *CRI) toBigParallel() *big.Int {
+	var mu sync.Mutex
+	b := new(big.Int)
+	c.e.shard(c.e.size, func(lo, hi int) {
+		s, t := new(big.Int), new(big.Int)
+		for i := lo; i < hi; i++ {
+			s.Add(s, t.Mul(t.SetInt64(c.rm[i]), c.e.coprimes[i]))
+		}
+		mu.Lock()
+		b.Add(b, s)
+		mu.Unlock()
+	})
+	return b.Mod(b, c.e.limit)
+}
+
+// BatchMul computes dst[i] = a[i]*b[i], for all i.
+// The slices should have the same length, and all CRI should belong to e.
+// dst[i] may alias a[i] or b[i], but not a CRI at another index.
+func (e *CREngine) BatchMul(dst, a, b []*CRI) {
+	e.batch(len(dst), func(i int) { dst[i].mulLanes(a[i], b[i], 0, e.size) })
+}
+
+// BatchAdd computes dst[i] = a[i]+b[i], for all i.
+// The slices should have the same length, and all CRI should belong to e.
+// dst[i] may alias a[i] or b[i], but not a CRI at another index.
+func (e *CREngine) BatchAdd(dst, a, b []*CRI) {
+	e.batch(len(dst), func(i int) { dst[i].addLanes(a[i], b[i], 0, e.size) })
+}
+
+// BatchToBig returns the big.Int representations of all the CRIs in src.
+func (e *CREngine) BatchToBig(src []*CRI) []*big.Int {
+	res := make([]*big.Int, len(src))
+	e.batch(len(src), func(i int) {
+		c := src[i]
+		res[i] = e.fromGroupDigits(c.groupDigits(make([]uint64, len(e.mrGroups))))
+	})
+	return res
+}
+
+// batch runs f for all i in [0, n), sharding across the workers if n*size residues reach the threshold.
+func (e *CREngine) batch(n int, f func(i int)) {
+	run := func(lo, hi int) {
+		for i := lo; i < hi; i++ {
+			f(i)
+		}
+	}
+	if e.serial(n * e.size) {
+		run(0, n)
+	} else {
+		e.shard(n, run)
+	}
+}
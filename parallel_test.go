@@ -0,0 +1,59 @@
+package chinrem
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+)
+
+func TestParallel(t *testing.T) {
+	rd := rand.New(rand.NewSource(42))
+
+	for _, e := range []*CREngine{NewCREngine(3), NewCREngine(50), NewCREngine62(7)} {
+		pe := e.Parallel(4, 1) // always parallel
+		if pe.Workers() != 4 || e.Workers() != 1 {
+			t.Fatalf("unexpected workers : %d %d", pe.Workers(), e.Workers())
+		}
+
+		var as, bs, dst []*CRI
+		for i := 0; i < 50; i++ {
+			a, b := e.NewCRIRand(rd), e.NewCRIRand(rd)
+			pa, pb := pe.NewCRI().Set(a), pe.NewCRI().Set(b)
+			as, bs, dst = append(as, pa), append(bs, pb), append(dst, pe.NewCRI())
+
+			if !pe.NewCRI().Mul(pa, pb).Equal(e.NewCRI().Mul(a, b)) {
+				t.Fatal("parallel Mul failed")
+			}
+			if !pe.NewCRI().Add(pa, pb).Equal(e.NewCRI().Add(a, b)) {
+				t.Fatal("parallel Add failed")
+			}
+			n := big.NewInt(rd.Int63())
+			if !pe.NewCRI().Exp(pa, n).Equal(e.NewCRI().Exp(a, n)) {
+				t.Fatal("parallel Exp failed")
+			}
+			pc, c := pe.NewCRI(), e.NewCRI()
+			perr, err := pc.Inv(pa), c.Inv(a)
+			if perr != err || (err == nil && !pc.Equal(c)) {
+				t.Fatal("parallel Inv failed", perr, err)
+			}
+			if pa.ToBig().Cmp(a.ToBig()) != 0 {
+				t.Fatal("parallel ToBig failed")
+			}
+		}
+
+		pe.BatchMul(dst, as, bs)
+		for i := range dst {
+			if !dst[i].Equal(e.NewCRI().Mul(as[i], bs[i])) {
+				t.Fatal("BatchMul failed")
+			}
+		}
+		pe.BatchAdd(dst, dst, bs) // aliasing
+		for i, b := range pe.BatchToBig(dst) {
+			want := new(big.Int).Mul(as[i].ToBig(), bs[i].ToBig())
+			want.Add(want, bs[i].ToBig()).Mod(want, e.Limit())
+			if b.Cmp(want) != 0 {
+				t.Fatal("BatchAdd or BatchToBig failed")
+			}
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package chinrem
+
+import (
+	"fmt"
+	"math/bits"
+)
+
+// PrimeWidth selects the kind of primes used to build a CREngine.
+type PrimeWidth int
+
+const (
+	// SmallPrimes are the consecutive primes, starting from 2, as used by NewCREngine.
+	SmallPrimes PrimeWidth = iota
+	// Primes31 are the largest primes below 2^31, in decreasing order.
+	// They are still packed by pairs, or more, during mixed-radix conversion.
+	Primes31
+	// Primes62 are the largest primes below 2^62, in decreasing order.
+	// They minimize the number of lanes for a given Limit.
+	Primes62
+)
+
+// generator returns a function that provides the successive primes of width w.
+func (w PrimeWidth) generator() func() int64 {
+	switch w {
+	case SmallPrimes:
+		p := int64(1)
+		return func() int64 {
+			p++
+			for !isPrime(p) {
+				p++
+			}
+			return p
+		}
+	case Primes31, Primes62:
+		p := int64(1) << w.bits()
+		return func() int64 {
+			p--
+			for !isPrime(p) {
+				p--
+			}
+			return p
+		}
+	default:
+		panic(fmt.Sprintf("unknown prime width : %d", w))
+	}
+}
+
+// bits is a lower estimate of the number of bits carried by each prime of width w.
+func (w PrimeWidth) bits() int {
+	switch w {
+	case Primes31:
+		return 31
+	case Primes62:
+		return 62
+	default:
+		return 1
+	}
+}
+
+// minSize is the minimum number of primes of an engine.
+func (w PrimeWidth) minSize() int {
+	if w == SmallPrimes {
+		return 3
+	}
+	return 1
+}
+
+// mulmod computes a*b modulo m, without overflow, for any m up to 2^63.
+// a and b should be normalized, ie 0 <= a, b < m.
+func mulmod(a, b, m int64) int64 {
+	hi, lo := bits.Mul64(uint64(a), uint64(b))
+	if hi == 0 {
+		return int64(lo % uint64(m))
+	}
+	return int64(bits.Rem64(hi, lo, uint64(m)))
+}
+
+// isPrime is a deterministic Miller-Rabin primality test, valid for all int64.
+func isPrime(n int64) bool {
+	if n < 2 {
+		return false
+	}
+	for _, p := range []int64{2, 3, 5, 7, 11, 13, 17, 19, 23, 29, 31, 37} {
+		if n%p == 0 {
+			return n == p
+		}
+	}
+
+	// n-1 = d * 2^s, with d odd
+	d, s := n-1, 0
+	for d%2 == 0 {
+		d, s = d/2, s+1
+	}
+
+	// these bases are enough for any n < 3.3e24
+	for _, a := range []int64{2, 3, 5, 7, 11, 13, 17, 19, 23, 29, 31, 37} {
+		x := expi(a, d, n)
+		if x == 1 || x == n-1 {
+			continue
+		}
+		composite := true
+		for r := 1; r < s; r++ {
+			x = mulmod(x, x, n)
+			if x == n-1 {
+				composite = false
+				break
+			}
+		}
+		if composite {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,82 @@
+package chinrem
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+)
+
+func TestIsPrime(t *testing.T) {
+	rd := rand.New(rand.NewSource(42))
+	for i := 0; i < 2000; i++ {
+		n := rd.Int63()
+		if i < 1000 {
+			n = int64(i)
+		}
+		if want := big.NewInt(n).ProbablyPrime(0); isPrime(n) != want {
+			t.Fatalf("isPrime(%d) : got %v, want %v", n, !want, want)
+		}
+	}
+	// strong pseudoprimes to several bases
+	for _, n := range []int64{3215031751, 2152302898747, 3474749660383, 341550071728321, 3825123056546413051} {
+		if isPrime(n) {
+			t.Fatalf("%d is not prime", n)
+		}
+	}
+}
+
+func TestMulmod(t *testing.T) {
+	rd := rand.New(rand.NewSource(42))
+	for i := 0; i < 1000; i++ {
+		m := rd.Int63n(1<<62) + 2
+		a, b, c := rd.Int63n(m), rd.Int63n(m), rd.Int63()
+		want := new(big.Int).Mul(big.NewInt(a), big.NewInt(b))
+		if got := mulmod(a, b, m); new(big.Int).Mod(want, big.NewInt(m)).Int64() != got {
+			t.Fatalf("mulmod(%d, %d, %d) = %d", a, b, m, got)
+		}
+		want.Add(want, big.NewInt(c))
+		if got := mulAddMod(a, b, c, m); new(big.Int).Mod(want, big.NewInt(m)).Int64() != got {
+			t.Fatalf("mulAddMod(%d, %d, %d, %d) = %d", a, b, c, m, got)
+		}
+	}
+}
+
+func TestEngine62(t *testing.T) {
+	e := NewCREngine62(5)
+	e.verifyCoprimes(t)
+	for i, p := range e.primes {
+		if p >= 1<<62 || !big.NewInt(p).ProbablyPrime(20) {
+			t.Fatalf("invalid prime %d", p)
+		}
+		if i > 0 && p >= e.primes[i-1] {
+			t.Fatalf("primes should be decreasing : %v", e.primes)
+		}
+	}
+	if e.Limit().BitLen() != 5*62 {
+		t.Fatalf("unexpected limit bit length %d", e.Limit().BitLen())
+	}
+
+	rd := rand.New(rand.NewSource(42))
+	for i := 0; i < 200; i++ {
+		a, b := e.NewCRIRand(rd), e.NewCRIRand(rd)
+		ab, bb := a.ToBig(), b.ToBig()
+		if got := a.Cmp(b); got != ab.Cmp(bb) {
+			t.Fatalf("Cmp : got %d, want %d", got, ab.Cmp(bb))
+		}
+		if e.NewCRIBig(ab).Cmp(a) != 0 {
+			t.Fatal("big round trip failed")
+		}
+
+		n := big.NewInt(rd.Int63())
+		checkBig(t, "Exp", e.NewCRI().Exp(a, n), new(big.Int).Exp(ab, n, e.Limit()))
+
+		if c := e.NewCRI(); c.Inv(a) == nil {
+			checkBig(t, "Inv", c, new(big.Int).ModInverse(ab, e.Limit()))
+		}
+
+		q := e.NewCRI()
+		if err := q.Quo(a, b); err == nil {
+			checkBig(t, "Quo", q.Mul(q, b), ab)
+		}
+	}
+}
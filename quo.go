@@ -0,0 +1,162 @@
+package chinrem
+
+import (
+	"math/big"
+)
+
+// The solutions q of b*q = a modulo Limit are studied lane by lane. On lane i, with modulus m and g = gcd(b[i], m),
+// there is a solution only if g divides a[i], and the solutions are then q[i] + k*step[i], for 0 <= k < g, with step[i] = m/g.
+// On a prime lane, there is either a single solution, or, when a[i] = b[i] = 0, a free lane where any value is a solution.
+// The solutions modulo Limit are all the combinations of the lane solutions.
+
+// quoLanes computes, for each lane, the smallest solution q[i] and the step step[i] between solutions.
+// It returns ErrNotDivisible if there is no solution, or ErrDivideByZero if b is 0.
+func (e *CREngine) quoLanes(a, b *CRI, q, step []int64) error {
+	bIsZero := true
+	for i, m := range e.primes {
+		ai, bi := modInt64(a.rm[i], m), modInt64(b.rm[i], m)
+		if bi != 0 {
+			bIsZero = false
+		}
+		g, _, _ := gcd(bi, m)
+		if ai%g != 0 {
+			return ErrNotDivisible
+		}
+		s := m / g
+		step[i] = s
+		if s == 1 {
+			q[i] = 0
+			continue
+		}
+		q[i] = mulmod(invmod(bi/g%s, s), ai/g%s, s)
+	}
+	if bIsZero {
+		return ErrDivideByZero
+	}
+	return nil
+}
+
+// QuoFree computes a quotient q of a/b modulo Limit, such that a = bq modulo Limit, and stores it in c.
+// Unlike Quo, it reports the free lanes : free[i] is true if lane i has more than one solution,
+// and the value chosen for c on that lane is then the smallest one.
+// It returns the same errors as Quo, leaving c unchanged.
+func (c *CRI) QuoFree(a, b *CRI) (free []bool, err error) {
+	q, step := make([]int64, c.e.size), make([]int64, c.e.size)
+	if err := c.e.quoLanes(a, b, q, step); err != nil {
+		return nil, err
+	}
+	free = make([]bool, c.e.size)
+	for i, m := range c.e.primes {
+		free[i] = step[i] != m
+	}
+	copy(c.rm, q)
+	return free, nil
+}
+
+// QuoCount returns the number of solutions q of b*q = a modulo Limit.
+// It returns the same errors as Quo.
+func (e *CREngine) QuoCount(a, b *CRI) (*big.Int, error) {
+	q, step := make([]int64, e.size), make([]int64, e.size)
+	if err := e.quoLanes(a, b, q, step); err != nil {
+		return nil, err
+	}
+	n, t := big.NewInt(1), new(big.Int)
+	for i, m := range e.primes {
+		if s := step[i]; s != m {
+			n.Mul(n, t.SetInt64(m/s))
+		}
+	}
+	return n, nil
+}
+
+// QuoAll calls f with each solution q of b*q = a modulo Limit, until f returns false.
+// The CRI passed to f is reused between calls, and should be cloned if it needs to be kept.
+// It returns the same errors as Quo.
+func (e *CREngine) QuoAll(a, b *CRI, f func(q *CRI) bool) error {
+	q, step := make([]int64, e.size), make([]int64, e.size)
+	if err := e.quoLanes(a, b, q, step); err != nil {
+		return err
+	}
+	r := e.NewCRISlice(q)
+
+	// Odometer enumeration of the free lanes, restarting a lane from its smallest solution when it overflows.
+	for {
+		if !f(r) {
+			return nil
+		}
+		i := 0
+		for ; i < e.size; i++ {
+			if step[i] == e.primes[i] {
+				continue
+			}
+			r.rm[i] += step[i]
+			if r.rm[i] < e.primes[i] {
+				break
+			}
+			r.rm[i] = q[i]
+		}
+		if i == e.size {
+			return nil
+		}
+	}
+}
+
+// QuoMin computes the smallest q in [0, Limit) such that b*q = a modulo Limit, and stores it in c.
+// It returns the same errors as Quo, leaving c unchanged.
+func (c *CRI) QuoMin(a, b *CRI) error {
+	q, step := make([]int64, c.e.size), make([]int64, c.e.size)
+	if err := c.e.quoLanes(a, b, q, step); err != nil {
+		return err
+	}
+	for i, m := range c.e.primes {
+		if step[i] != m {
+			c.e.setGarner(c, q, step)
+			return nil
+		}
+	}
+	copy(c.rm, q) // unique solution
+	return nil
+}
+
+// DivExact computes the integer quotient a/b, and stores it in c, when b is known to divide a as integers.
+// Unlike Quo, it also handles the lanes where b is 0, as long as b != 0.
+// The result is only meaningful if b divides a, with 0 <= a < Limit : this is not checked.
+// If a is not divisible by b modulo Limit, it returns ErrNotDivisible, and if b is 0, ErrDivideByZero.
+//
+// The quotient q verifies b*q = a modulo Limit, so it is known modulo step[i] on each lane (see quoLanes).
+// The product of the step[i] is Limit/gcd(b, Limit), that is at least Limit/b, and q < Limit/b.
+// Hence q is the smallest solution, as computed by QuoMin.
+func (c *CRI) DivExact(a, b *CRI) error {
+	return c.QuoMin(a, b)
+}
+
+// setGarner sets c to the smallest x >= 0 such that x = r[i] modulo s[i], for all i.
+// The s[i] should be pairwise coprime divisors of the engine moduli, and r[i] should be normalized.
+// Moduli s[i] equal to 1 impose no constraint.
+func (e *CREngine) setGarner(c *CRI, r, s []int64) {
+	// Mixed-radix digits of x, relative to s : x = d[0] + d[1]*s[0] + d[2]*s[0]*s[1] + ...
+	d := make([]int64, len(s))
+	for k, sk := range s {
+		if sk == 1 {
+			continue
+		}
+		// v = x modulo sk, using the digits known so far, and p = s[0]*...*s[k-1] modulo sk.
+		var v, p int64 = 0, 1 % sk
+		for j := k - 1; j >= 0; j-- {
+			v = mulAddMod(v, s[j]%sk, d[j]%sk, sk)
+		}
+		for j := 0; j < k; j++ {
+			p = mulmod(p, s[j]%sk, sk)
+		}
+		d[k] = mulmod(modInt64(r[k]-v, sk), invmod(p, sk), sk)
+	}
+
+	// Evaluate x modulo each engine modulus.
+	for i, m := range e.primes {
+		var y int64
+		for j := len(d) - 1; j >= 0; j-- {
+			y = mulAddMod(y, s[j]%m, d[j]%m, m)
+		}
+		c.rm[i] = y
+	}
+}
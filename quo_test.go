@@ -0,0 +1,109 @@
+package chinrem
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+)
+
+func TestQuoSolutions(t *testing.T) {
+	pp, _ := NewCREngineModuli([]int64{4, 9, 5})
+	for _, e := range []*CREngine{NewCREngine(4), pp} {
+		n := e.Limit().Int64()
+		for a := int64(0); a < n; a++ {
+			for b := int64(0); b < n; b += 7 {
+				var want []int64
+				for q := int64(0); q < n; q++ {
+					if b*q%n == a {
+						want = append(want, q)
+					}
+				}
+				ca, cb := e.NewCRIInt64(a), e.NewCRIInt64(b)
+
+				c := e.NewCRI()
+				err := c.QuoMin(ca, cb)
+				if b == 0 {
+					if err == nil {
+						t.Fatalf("%d/%d should fail", a, b)
+					}
+					continue
+				}
+				if len(want) == 0 {
+					if err != ErrNotDivisible {
+						t.Fatalf("%d/%d should not be divisible, got %v", a, b, err)
+					}
+					continue
+				}
+				if err != nil || c.ToBig().Int64() != want[0] {
+					t.Fatalf("QuoMin %d/%d : got %v, want %d (%v)", a, b, c, want[0], err)
+				}
+
+				if cnt, err := e.QuoCount(ca, cb); err != nil || cnt.Int64() != int64(len(want)) {
+					t.Fatalf("QuoCount %d/%d : got %v, want %d (%v)", a, b, cnt, len(want), err)
+				}
+
+				got := make(map[int64]bool)
+				e.QuoAll(ca, cb, func(q *CRI) bool {
+					got[q.ToBig().Int64()] = true
+					return true
+				})
+				if len(got) != len(want) {
+					t.Fatalf("QuoAll %d/%d : got %v, want %v", a, b, got, want)
+				}
+				for _, q := range want {
+					if !got[q] {
+						t.Fatalf("QuoAll %d/%d : missing %d", a, b, q)
+					}
+				}
+
+				free, err := c.QuoFree(ca, cb)
+				if err != nil || !c.Mul(c, cb).Equal(ca) {
+					t.Fatalf("QuoFree %d/%d : not a solution (%v)", a, b, err)
+				}
+				nfree := 0
+				for _, f := range free {
+					if f {
+						nfree++
+					}
+				}
+				if (nfree == 0) != (len(want) == 1) {
+					t.Fatalf("QuoFree %d/%d : unexpected mask %v", a, b, free)
+				}
+			}
+		}
+	}
+}
+
+func TestDivExact(t *testing.T) {
+	rd := rand.New(rand.NewSource(42))
+	pp, _ := NewCREngineModuli([]int64{64, 81, 25, 49, 11, 13, 17, 19, 23})
+
+	for _, e := range []*CREngine{NewCREngine(30), pp, NewCREngine62(3)} {
+		// binomial coefficients, dividing by numbers that share primes with the base
+		const n = 60
+		r := e.NewCRIInt64(1)
+		for k := int64(0); k < n; k++ {
+			want := new(big.Int).Binomial(n, k)
+			checkBig(t, "DivExact binomial", r, want)
+			if want.Mul(want, big.NewInt(n-k)).Cmp(e.Limit()) >= 0 {
+				break // the next product would wrap
+			}
+			r.MulInt64(r, n-k)
+			if err := r.DivExact(r, e.NewCRIInt64(k+1)); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		for i := 0; i < 200; i++ {
+			b := new(big.Int).Rand(rd, new(big.Int).Rsh(e.Limit(), uint(rd.Intn(e.Limit().BitLen()))))
+			b.Add(b, big.NewInt(1))
+			q := new(big.Int).Rand(rd, new(big.Int).Quo(e.Limit(), b))
+			a := new(big.Int).Mul(q, b)
+			c := e.NewCRI()
+			if err := c.DivExact(e.NewCRIBig(a), e.NewCRIBig(b)); err != nil {
+				t.Fatal(err)
+			}
+			checkBig(t, "DivExact", c, q)
+		}
+	}
+}
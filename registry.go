@@ -0,0 +1,119 @@
+package chinrem
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sync"
+)
+
+// Registry is a set of engines, keyed by fingerprint, so that each base is only built once.
+// It lets decoders find the engine of an incoming CRI. It is safe for concurrent use.
+type Registry struct {
+	mu      sync.RWMutex
+	engines map[uint64]*CREngine
+}
+
+// DefaultRegistry is the process-wide registry. It is used to decode CRIs that have no engine yet.
+var DefaultRegistry = NewRegistry()
+
+// NewRegistry creates an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{engines: make(map[uint64]*CREngine)}
+}
+
+// Register adds e to the registry, and returns the registered engine with the same base,
+// that is e itself if its base was not registered yet.
+// It returns ErrEngineMismatch in the very unlikely case where another base has the same fingerprint.
+func (r *Registry) Register(e *CREngine) (*CREngine, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if re, ok := r.engines[e.fingerprint]; ok {
+		if !sameModuli(re.primes, e.primes) {
+			return nil, fmt.Errorf("%w : fingerprint collision for %016x", ErrEngineMismatch, e.fingerprint)
+		}
+		return re, nil
+	}
+	r.engines[e.fingerprint] = e
+	return e, nil
+}
+
+// Lookup returns the registered engine with the provided fingerprint, if any.
+func (r *Registry) Lookup(fingerprint uint64) (*CREngine, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	e, ok := r.engines[fingerprint]
+	return e, ok
+}
+
+// Len is the number of registered engines.
+func (r *Registry) Len() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.engines)
+}
+
+// Moduli returns the registered engine for the provided moduli, creating and registering it if needed.
+// The moduli are validated as in NewCREngineModuli.
+func (r *Registry) Moduli(moduli []int64) (*CREngine, error) {
+	r.mu.RLock()
+	e, ok := r.engines[fingerprint(moduli)]
+	r.mu.RUnlock()
+	if ok && sameModuli(e.primes, moduli) {
+		return e, nil
+	}
+	e, err := NewCREngineModuli(moduli)
+	if err != nil {
+		return nil, err
+	}
+	return r.Register(e)
+}
+
+// Width returns the registered engine for the first size primes of the specified width, creating and registering it if needed.
+// It is the cached equivalent of NewCREngineWidth, and of NewCREngine for SmallPrimes.
+func (r *Registry) Width(size int, width PrimeWidth) *CREngine {
+	e, err := r.Moduli(widthPrimes(size, width))
+	if err != nil {
+		panic(err) // generated primes are always valid
+	}
+	return e
+}
+
+// UnmarshalEngine decodes the binary encoding of an engine, and returns the registered engine with that base,
+// creating and registering it if needed.
+func (r *Registry) UnmarshalEngine(data []byte) (*CREngine, error) {
+	moduli, err := decodeModuli(data)
+	if err != nil {
+		return nil, err
+	}
+	return r.Moduli(moduli)
+}
+
+// UnmarshalCRI decodes the binary encoding of a CRI, into a new CRI of the registered engine with the same fingerprint.
+// It returns ErrEngineMismatch if no such engine is registered.
+func (r *Registry) UnmarshalCRI(data []byte) (*CRI, error) {
+	if len(data) < 9 {
+		return nil, fmt.Errorf("%w : unknown binary format", ErrMalformed)
+	}
+	e, ok := r.Lookup(binary.BigEndian.Uint64(data[1:]))
+	if !ok {
+		return nil, fmt.Errorf("%w : engine %016x is not registered", ErrEngineMismatch, binary.BigEndian.Uint64(data[1:]))
+	}
+	c := e.NewCRI()
+	if err := c.UnmarshalBinary(data); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// sameModuli is true if both bases are identical.
+func sameModuli(a, b []int64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
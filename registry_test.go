@@ -0,0 +1,99 @@
+package chinrem
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestEngineMarshal(t *testing.T) {
+	pp, _ := NewCREngineModuli([]int64{64, 81, 25, 49, 11})
+	for _, e := range []*CREngine{NewCREngine(30), NewCREngine62(4), pp} {
+		bin, _ := e.MarshalBinary()
+		js, err := json.Marshal(e)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var gb bytes.Buffer
+		if err := gob.NewEncoder(&gb).Encode(e); err != nil {
+			t.Fatal(err)
+		}
+
+		decoded := []*CREngine{new(CREngine), new(CREngine), new(CREngine)}
+		if err := decoded[0].UnmarshalBinary(bin); err != nil {
+			t.Fatal(err)
+		}
+		if err := json.Unmarshal(js, decoded[1]); err != nil {
+			t.Fatal(err)
+		}
+		if err := gob.NewDecoder(&gb).Decode(decoded[2]); err != nil {
+			t.Fatal(err)
+		}
+		for _, d := range decoded {
+			if d.Fingerprint() != e.Fingerprint() || d.Limit().Cmp(e.Limit()) != 0 {
+				t.Fatalf("engine round trip failed : %v", d.Moduli())
+			}
+		}
+	}
+
+	bin, _ := NewCREngine(3).MarshalBinary()
+	for _, b := range [][]byte{nil, {2}, bin[:len(bin)-1], append(bin, 0)} {
+		if err := new(CREngine).UnmarshalBinary(b); !errors.Is(err, ErrMalformed) {
+			t.Fatalf("decoding %v should fail : %v", b, err)
+		}
+	}
+	if err := json.Unmarshal([]byte(`{"version":1,"moduli":[6,9]}`), new(CREngine)); !errors.Is(err, ErrNotCoprime) {
+		t.Fatal("decoding an invalid base should fail", err)
+	}
+	for _, js := range []string{`{"moduli":[5,7]}`, `{"version":2,"moduli":[5,7]}`, `{"version":"0.3.0","moduli":[5,7]}`} {
+		if err := json.Unmarshal([]byte(js), new(CREngine)); !errors.Is(err, ErrMalformed) {
+			t.Fatalf("decoding %s should fail : %v", js, err)
+		}
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+
+	e := r.Width(20, Primes31)
+	if r.Width(20, Primes31) != e || r.Len() != 1 {
+		t.Fatal("Width should reuse the registered engine")
+	}
+	if re, err := r.Register(NewCREngineWidth(20, Primes31)); err != nil || re != e {
+		t.Fatal("Register should return the registered engine", err)
+	}
+	if r.Width(20, SmallPrimes).Fingerprint() != NewCREngine(20).Fingerprint() {
+		t.Fatal("Width with SmallPrimes should match NewCREngine")
+	}
+	if _, err := r.Moduli([]int64{6, 9}); !errors.Is(err, ErrNotCoprime) {
+		t.Fatal("Moduli should validate the base", err)
+	}
+
+	bin, _ := NewCREngineWidth(20, Primes31).MarshalBinary()
+	if d, err := r.UnmarshalEngine(bin); err != nil || d != e {
+		t.Fatal("UnmarshalEngine should return the registered engine", err)
+	}
+
+	a := e.NewCRIInt64(123456789)
+	data, _ := a.MarshalBinary()
+	if c, err := r.UnmarshalCRI(data); err != nil || !c.Equal(a) || c.e != e {
+		t.Fatal("UnmarshalCRI failed", err)
+	}
+	data, _ = NewCREngine(7).NewCRIInt64(1).MarshalBinary()
+	if _, err := r.UnmarshalCRI(data); !errors.Is(err, ErrEngineMismatch) {
+		t.Fatal("UnmarshalCRI should fail for an unknown engine", err)
+	}
+
+	// Decoding into a CRI without engine uses DefaultRegistry.
+	de, err := DefaultRegistry.Moduli([]int64{1009, 1013, 1019})
+	if err != nil {
+		t.Fatal(err)
+	}
+	js, _ := json.Marshal(de.NewCRIInt64(42))
+	c := new(CRI)
+	if err := json.Unmarshal(js, c); err != nil || c.e != de || c.ToBig().Int64() != 42 {
+		t.Fatal("decoding without engine should use DefaultRegistry", err)
+	}
+}
@@ -0,0 +1,93 @@
+package chinrem
+
+import (
+	"math/big"
+)
+
+// In the signed interpretation, a CRI represents a value in (-Limit/2, Limit/2].
+// A value x in [0, Limit) is considered negative when x > Limit/2, and then stands for x - Limit.
+// Nothing changes in the residues, only the interpretation of the value differs.
+
+// initSigned precomputes the group mixed-radix digits of Limit/2 (rounded down), used to detect negative values.
+func (e *CREngine) initSigned() {
+	h := new(big.Int).Rsh(e.limit, 1)
+	e.half = e.NewCRIBig(h).groupDigits(make([]uint64, len(e.mrGroups)))
+}
+
+// Sign returns, using the signed interpretation :
+//
+//	-1 if c < 0
+//	 0 if c == 0
+//	+1 if c > 0
+//
+// Normalization is not required.
+func (c *CRI) Sign() int {
+	d := c.groupDigits(make([]uint64, len(c.e.mrGroups)))
+	return c.e.signDigits(d)
+}
+
+// signDigits returns the sign of the value with the provided group mixed-radix digits.
+func (e *CREngine) signDigits(d []uint64) int {
+	if cmpDigits(d, e.half) > 0 {
+		return -1
+	}
+	for _, di := range d {
+		if di != 0 {
+			return +1
+		}
+	}
+	return 0
+}
+
+// ToBigSigned returns the big.Int representation of c, using the signed interpretation.
+// The result is in (-Limit/2, Limit/2].
+// Normalization is not required.
+func (c *CRI) ToBigSigned() *big.Int {
+	d := c.groupDigits(make([]uint64, len(c.e.mrGroups)))
+	b := c.e.fromGroupDigits(d)
+	if c.e.signDigits(d) < 0 {
+		b.Sub(b, c.e.limit)
+	}
+	return b
+}
+
+// Abs sets c to the absolute value of a, using the signed interpretation, and returns c.
+// Normalization is not required, and c is normalized.
+func (c *CRI) Abs(a *CRI) *CRI {
+	neg := a.Sign() < 0
+	for i, p := range c.e.primes {
+		r := modInt64(a.rm[i], p)
+		if neg && r != 0 {
+			r = p - r
+		}
+		c.rm[i] = r
+	}
+	return c
+}
+
+// CmpSigned compares c and a, using the signed interpretation, and returns:
+//
+//	-1 if c < a
+//	 0 if c == a
+//	+1 if c > a
+//
+// Normalization is not required.
+// If the bases differ, the order is given by the engines, as described in cmpEngines, and c and a are never equal.
+func (c *CRI) CmpSigned(a *CRI) int {
+
+	if !SameEngine(a, c) {
+		return c.cmpEngines(a)
+	}
+
+	n := len(c.e.mrGroups)
+	dc, da := c.groupDigits(make([]uint64, n)), a.groupDigits(make([]uint64, n))
+	sc, sa := c.e.signDigits(dc), c.e.signDigits(da)
+	switch {
+	case sc < 0 && sa >= 0:
+		return -1
+	case sc >= 0 && sa < 0:
+		return +1
+	default: // same sign, x - Limit preserves the order between negative values.
+		return cmpDigits(dc, da)
+	}
+}
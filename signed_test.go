@@ -0,0 +1,70 @@
+package chinrem
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+)
+
+func TestSignedSmall(t *testing.T) {
+	e := NewCREngine(10)
+	for i := int64(-1000); i <= 1000; i++ {
+		a := e.NewCRIInt64(i)
+		if got := a.ToBigSigned().Int64(); got != i {
+			t.Fatalf("ToBigSigned : got %d, want %d", got, i)
+		}
+		want := 0
+		switch {
+		case i < 0:
+			want = -1
+		case i > 0:
+			want = +1
+		}
+		if a.Sign() != want {
+			t.Fatalf("Sign of %d : got %d, want %d", i, a.Sign(), want)
+		}
+		abs := i
+		if abs < 0 {
+			abs = -abs
+		}
+		if got := e.NewCRI().Abs(a).ToBig().Int64(); got != abs {
+			t.Fatalf("Abs of %d : got %d", i, got)
+		}
+		u := a.Clone() // not normalized
+		for j, p := range e.primes {
+			u.rm[j] += p * int64(j%3-1)
+		}
+		if got := u.Abs(u); !got.Equal(e.NewCRIInt64(abs)) {
+			t.Fatalf("Abs of %d, not normalized : got %v", i, got)
+		}
+	}
+}
+
+func TestSignedBounds(t *testing.T) {
+	for _, size := range []int{3, 10, 20} {
+		e := NewCREngine(size)
+		h := new(big.Int).Rsh(e.Limit(), 1)
+
+		a := e.NewCRIBig(h) // Limit/2 is positive
+		if a.Sign() != +1 || a.ToBigSigned().Cmp(h) != 0 {
+			t.Fatalf("Limit/2 should be positive : %v", a.ToBigSigned())
+		}
+		h.Add(h, big.NewInt(1))
+		a.SetBig(h) // Limit/2 + 1 is negative
+		if a.Sign() != -1 || a.ToBigSigned().Cmp(h.Sub(h, e.Limit())) != 0 {
+			t.Fatalf("Limit/2+1 should be negative : %v", a.ToBigSigned())
+		}
+	}
+}
+
+func TestCmpSigned(t *testing.T) {
+	e := NewCREngine(10)
+	rd := rand.New(rand.NewSource(42))
+	for i := 0; i < 500; i++ {
+		a, b := e.NewCRIRand(rd), e.NewCRIRand(rd)
+		want := a.ToBigSigned().Cmp(b.ToBigSigned())
+		if got := a.CmpSigned(b); got != want {
+			t.Fatalf("CmpSigned(%v, %v) : got %d, want %d", a.ToBigSigned(), b.ToBigSigned(), got, want)
+		}
+	}
+}
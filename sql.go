@@ -0,0 +1,112 @@
+package chinrem
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"math/big"
+)
+
+// SQLFormat selects how a CRI is stored in a database column.
+type SQLFormat int
+
+const (
+	// SQLDecimal stores the value, in [0, Limit), as a decimal text column.
+	SQLDecimal SQLFormat = iota
+	// SQLBlob stores the residues, with the engine fingerprint, as a binary column (see MarshalBinary).
+	SQLBlob
+)
+
+// SQLValue is a CRI bound to its engine, that implements sql.Scanner and driver.Valuer.
+// A NULL column is scanned as Valid = false, leaving the CRI unchanged.
+type SQLValue struct {
+	CRI    *CRI
+	Format SQLFormat
+	Valid  bool // Valid is true if the value is not NULL
+}
+
+// SQL returns a valid SQLValue, bound to c, to store c or to scan into c.
+func (c *CRI) SQL(format SQLFormat) *SQLValue {
+	return &SQLValue{CRI: c, Format: format, Valid: true}
+}
+
+var errNoCRI = fmt.Errorf("SQLValue has no CRI")
+
+// Value implements driver.Valuer.
+func (v *SQLValue) Value() (driver.Value, error) {
+	if !v.Valid {
+		return nil, nil
+	}
+	if v.CRI == nil {
+		return nil, errNoCRI
+	}
+	switch v.Format {
+	case SQLDecimal:
+		return v.CRI.ToBig().String(), nil
+	case SQLBlob:
+		return v.CRI.MarshalBinary()
+	default:
+		return nil, fmt.Errorf("unknown SQL format : %d", v.Format)
+	}
+}
+
+// Scan implements sql.Scanner. The column is decoded according to Format.
+// A decimal column can be a string, a []byte or an int64, and a blob column should be a []byte.
+// A decimal value outside [0, Limit), such as a value written from a larger engine, returns an error wrapping ErrOverflow.
+// On error, the CRI is unchanged.
+func (v *SQLValue) Scan(src any) error {
+	if src == nil {
+		v.Valid = false
+		return nil
+	}
+	if v.CRI == nil {
+		return errNoCRI
+	}
+	var err error
+	switch v.Format {
+	case SQLDecimal:
+		switch s := src.(type) {
+		case string:
+			err = v.scanDecimal(s)
+		case []byte:
+			err = v.scanDecimal(string(s))
+		case int64:
+			err = v.setDecimal(big.NewInt(s))
+		default:
+			err = fmt.Errorf("%w : cannot scan %T into a decimal CRI", ErrMalformed, src)
+		}
+	case SQLBlob:
+		b, ok := src.([]byte)
+		if !ok {
+			return fmt.Errorf("%w : cannot scan %T into a blob CRI", ErrMalformed, src)
+		}
+		err = v.CRI.UnmarshalBinary(b)
+	default:
+		err = fmt.Errorf("unknown SQL format : %d", v.Format)
+	}
+	if err != nil {
+		return err
+	}
+	v.Valid = true
+	return nil
+}
+
+// scanDecimal sets the CRI to the value of the decimal integer s.
+func (v *SQLValue) scanDecimal(s string) error {
+	b, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		return fmt.Errorf("%w : %q is not a decimal integer", ErrMalformed, s)
+	}
+	return v.setDecimal(b)
+}
+
+// setDecimal sets the CRI to b, that should be in [0, Limit).
+func (v *SQLValue) setDecimal(b *big.Int) error {
+	if v.CRI.e == nil {
+		return fmt.Errorf("%w : the CRI to scan into has no engine", ErrEngineMismatch)
+	}
+	if b.Sign() < 0 || b.Cmp(v.CRI.e.limit) >= 0 {
+		return fmt.Errorf("%w : %d bits value %v is not in [0, Limit), for a %d bits Limit", ErrOverflow, b.BitLen(), b, v.CRI.e.limit.BitLen())
+	}
+	v.CRI.SetBig(b)
+	return nil
+}
@@ -0,0 +1,137 @@
+package chinrem
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"math/rand"
+	"sync"
+	"testing"
+)
+
+// stubDriver is an in-memory database/sql driver with a single table.
+// Any Exec appends its arguments as a row, and any Query returns all the rows.
+type stubDriver struct {
+	mu   sync.Mutex
+	rows [][]driver.Value
+}
+
+type stubConn struct{ d *stubDriver }
+type stubStmt struct{ d *stubDriver }
+type stubRows struct {
+	rows [][]driver.Value
+	cols int
+}
+
+var stub = new(stubDriver)
+
+func init() {
+	sql.Register("chinrem-stub", stub)
+}
+
+func (d *stubDriver) Open(name string) (driver.Conn, error) { return stubConn{d}, nil }
+
+func (c stubConn) Prepare(query string) (driver.Stmt, error) { return stubStmt(c), nil }
+func (c stubConn) Close() error                              { return nil }
+func (c stubConn) Begin() (driver.Tx, error)                 { return nil, errors.New("no transactions") }
+
+func (s stubStmt) Close() error  { return nil }
+func (s stubStmt) NumInput() int { return -1 }
+func (s stubStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.d.mu.Lock()
+	defer s.d.mu.Unlock()
+	s.d.rows = append(s.d.rows, args)
+	return driver.RowsAffected(1), nil
+}
+func (s stubStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.d.mu.Lock()
+	defer s.d.mu.Unlock()
+	return &stubRows{rows: append([][]driver.Value(nil), s.d.rows...), cols: 2}, nil
+}
+
+func (r *stubRows) Columns() []string { return []string{"dec", "blob"} }
+func (r *stubRows) Close() error      { return nil }
+func (r *stubRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+func TestSQL(t *testing.T) {
+	rd := rand.New(rand.NewSource(42))
+	e := NewCREngine(30)
+	db, err := sql.Open("chinrem-stub", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	var want []*CRI
+	for i := 0; i < 10; i++ {
+		a := e.NewCRIRand(rd)
+		want = append(want, a)
+		if _, err := db.Exec("INSERT", a.SQL(SQLDecimal), a.SQL(SQLBlob)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	null := &SQLValue{CRI: e.NewCRI()}
+	if _, err := db.Exec("INSERT", null, null); err != nil {
+		t.Fatal(err)
+	}
+
+	rows, err := db.Query("SELECT")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	for i := 0; rows.Next(); i++ {
+		dec, blob := e.NewCRI().SQL(SQLDecimal), e.NewCRI().SQL(SQLBlob)
+		if err := rows.Scan(dec, blob); err != nil {
+			t.Fatal(err)
+		}
+		if i == len(want) {
+			if dec.Valid || blob.Valid {
+				t.Fatal("NULL columns should not be valid")
+			}
+			continue
+		}
+		if !dec.Valid || !dec.CRI.Equal(want[i]) || !blob.Valid || !blob.CRI.Equal(want[i]) {
+			t.Fatalf("row %d : got %v and %v, want %v", i, dec.CRI, blob.CRI, want[i])
+		}
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	// decoding errors
+	v := NewCREngine(10).NewCRI().SQL(SQLBlob)
+	if b, _ := e.NewCRIInt64(1).MarshalBinary(); !errors.Is(v.Scan(b), ErrEngineMismatch) {
+		t.Fatal("scanning a blob from another engine should fail")
+	}
+	if v.Scan("12") == nil || e.NewCRI().SQL(SQLDecimal).Scan("abc") == nil {
+		t.Fatal("scanning an invalid column should fail")
+	}
+	if err := e.NewCRI().SQL(SQLDecimal).Scan(int64(3)); err != nil {
+		t.Fatal(err)
+	}
+
+	// decimal values outside [0, Limit)
+	small := NewCREngine(3).NewCRI()
+	for _, src := range []any{int64(-3), "-3", "30", []byte("1000"), e.Limit().String()} {
+		if err := small.SQL(SQLDecimal).Scan(src); !errors.Is(err, ErrOverflow) || !small.IsZero() {
+			t.Fatalf("scanning %v should overflow : %v", src, err)
+		}
+	}
+
+	// no CRI
+	if _, err := (&SQLValue{Valid: true}).Value(); err == nil {
+		t.Fatal("Value without CRI should fail")
+	}
+	if err := new(SQLValue).Scan("12"); err == nil {
+		t.Fatal("Scan without CRI should fail")
+	}
+}
@@ -0,0 +1,143 @@
+package chinrem
+
+import (
+	"fmt"
+	"math/big"
+	"math/bits"
+)
+
+// Square roots are computed lane by lane, with the Tonelli-Shanks algorithm.
+// Modulo an odd prime p, a non zero quadratic residue has exactly two roots, r and p-r, and 0 has the single root 0.
+// The square roots modulo Limit are all the combinations of these lane roots, so there are 2^k of them,
+// where k is the number of odd prime lanes with a non zero residue.
+
+var ErrNotPrimeBase = fmt.Errorf("operation requires a base of prime moduli")
+var ErrNotQuadraticResidue = fmt.Errorf("not a quadratic residue")
+
+// IsQuadraticResidue is true if c has a square root modulo Limit.
+// It returns ErrNotPrimeBase if the engine moduli are not all prime.
+func (c *CRI) IsQuadraticResidue() (bool, error) {
+	if !c.e.IsPrimeBase() {
+		return false, ErrNotPrimeBase
+	}
+	for i, p := range c.e.primes {
+		if !isQRLane(modInt64(c.rm[i], p), p) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// isQRLane uses the Euler criterion, for a normalized residue r modulo the prime p.
+func isQRLane(r, p int64) bool {
+	return r == 0 || p == 2 || expi(r, (p-1)/2, p) == 1
+}
+
+// Sqrt sets c to a square root of a, modulo Limit, and returns nil.
+// On each lane, the smallest of the two roots is selected, so the result does not depend on the representation of a.
+// If a has no square root, it returns ErrNotQuadraticResidue, and c is unchanged.
+// If the engine moduli are not all prime, it returns ErrNotPrimeBase.
+func (c *CRI) Sqrt(a *CRI) error {
+	if ok, err := a.IsQuadraticResidue(); err != nil || !ok {
+		if err == nil {
+			err = ErrNotQuadraticResidue
+		}
+		return err
+	}
+	for i, p := range c.e.primes {
+		r := sqrtLane(modInt64(a.rm[i], p), p)
+		if p-r < r {
+			r = p - r
+		}
+		c.rm[i] = r
+	}
+	return nil
+}
+
+// SquareRoots calls f with each square root of a, modulo Limit, until f returns false.
+// The first root is the one returned by Sqrt, and each following root differs from the previous one on a single lane.
+// The CRI passed to f is reused between calls, and should be cloned if it needs to be kept.
+// It returns the same errors as Sqrt.
+func (c *CRI) SquareRoots(f func(r *CRI) bool) error {
+	r := c.e.NewCRI()
+	if err := r.Sqrt(c); err != nil {
+		return err
+	}
+
+	// lanes that have two distinct roots
+	var free []int
+	for i, p := range c.e.primes {
+		if r.rm[i] != 0 && p != 2 {
+			free = append(free, i)
+		}
+	}
+
+	// Gray code enumeration : step k flips the lane given by the lowest set bit of k.
+	if !f(r) {
+		return nil
+	}
+	// With 64 free lanes or more, there are too many roots to ever complete the enumeration anyway.
+	for k := uint64(1); k != 0 && (len(free) >= 64 || k < 1<<len(free)); k++ {
+		i := free[bits.TrailingZeros64(k)]
+		r.rm[i] = c.e.primes[i] - r.rm[i]
+		if !f(r) {
+			return nil
+		}
+	}
+	return nil
+}
+
+// CountSquareRoots returns the number of square roots of c modulo Limit, possibly 0.
+// It returns ErrNotPrimeBase if the engine moduli are not all prime.
+func (c *CRI) CountSquareRoots() (*big.Int, error) {
+	if ok, err := c.IsQuadraticResidue(); err != nil || !ok {
+		return big.NewInt(0), err
+	}
+	k := 0
+	for i, p := range c.e.primes {
+		if modInt64(c.rm[i], p) != 0 && p != 2 {
+			k++
+		}
+	}
+	return new(big.Int).Lsh(big.NewInt(1), uint(k)), nil
+}
+
+// sqrtLane returns a square root of r modulo the prime p, using the Tonelli-Shanks algorithm.
+// r should be a normalized quadratic residue.
+func sqrtLane(r, p int64) int64 {
+	if r == 0 || p == 2 {
+		return r
+	}
+	if p%4 == 3 {
+		return expi(r, (p+1)/4, p)
+	}
+
+	// p - 1 = q * 2^s, with q odd
+	q, s := p-1, 0
+	for q%2 == 0 {
+		q /= 2
+		s++
+	}
+	// z is any non residue
+	z := int64(2)
+	for isQRLane(z, p) {
+		z++
+	}
+
+	m, cc, t, x := s, expi(z, q, p), expi(r, q, p), expi(r, (q+1)/2, p)
+	for t != 1 {
+		// find the least j such that t^(2^j) = 1
+		j, tt := 0, t
+		for tt != 1 {
+			tt = mulmod(tt, tt, p)
+			j++
+		}
+		b := cc
+		for k := 0; k < m-j-1; k++ {
+			b = mulmod(b, b, p)
+		}
+		m, cc = j, mulmod(b, b, p)
+		t, x = mulmod(t, cc, p), mulmod(x, b, p)
+	}
+	return x
+}
@@ -0,0 +1,93 @@
+package chinrem
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+)
+
+func TestSqrtSmall(t *testing.T) {
+	e := NewCREngine(5) // 2310
+	n := e.Limit().Int64()
+
+	roots := make(map[int64][]int64)
+	for y := int64(0); y < n; y++ {
+		roots[y*y%n] = append(roots[y*y%n], y)
+	}
+
+	for x := int64(0); x < n; x++ {
+		a := e.NewCRIInt64(x)
+		want := roots[x]
+		if ok, err := a.IsQuadraticResidue(); err != nil || ok != (len(want) > 0) {
+			t.Fatalf("IsQuadraticResidue(%d) should be %v : %v", x, len(want) > 0, err)
+		}
+		if n, err := a.CountSquareRoots(); err != nil || n.Int64() != int64(len(want)) {
+			t.Fatalf("CountSquareRoots(%d) : got %v, want %d : %v", x, n, len(want), err)
+		}
+		r := e.NewCRI()
+		if err := r.Sqrt(a); err != nil {
+			if len(want) > 0 || err != ErrNotQuadraticResidue {
+				t.Fatalf("Sqrt(%d) failed : %v", x, err)
+			}
+			continue
+		}
+		checkBig(t, "Sqrt", r.Mul(r, r), big.NewInt(x))
+
+		got := make(map[int64]bool)
+		a.SquareRoots(func(r *CRI) bool {
+			got[r.ToBig().Int64()] = true
+			return true
+		})
+		if len(got) != len(want) {
+			t.Fatalf("SquareRoots(%d) : got %v, want %v", x, got, want)
+		}
+		for _, y := range want {
+			if !got[y] {
+				t.Fatalf("SquareRoots(%d) : missing %d", x, y)
+			}
+		}
+	}
+}
+
+func TestSqrt(t *testing.T) {
+	rd := rand.New(rand.NewSource(42))
+
+	for _, e := range []*CREngine{NewCREngine(50), NewCREngineWidth(10, Primes31), NewCREngine62(5)} {
+		for i := 0; i < 200; i++ {
+			a := e.NewCRIRand(rd)
+			a.Mul(a, a)
+			r := e.NewCRI()
+			if err := r.Sqrt(a); err != nil {
+				t.Fatal(err)
+			}
+			if !r.Clone().Mul(r, r).Equal(a) {
+				t.Fatalf("Sqrt of %v : %v is not a root", a, r)
+			}
+			// aliasing
+			if err := a.Sqrt(a); err != nil || !a.Equal(r) {
+				t.Fatalf("aliased Sqrt failed : %v", err)
+			}
+		}
+
+		// stop the enumeration early
+		count := 0
+		e.NewCRIInt64(4).SquareRoots(func(r *CRI) bool {
+			count++
+			return count < 10
+		})
+		if count != 10 {
+			t.Fatalf("enumeration should stop after 10 roots, got %d", count)
+		}
+	}
+
+	e, _ := NewCREngineModuli([]int64{9, 5, 7})
+	if err := e.NewCRIInt64(4).Sqrt(e.NewCRIInt64(4)); err != ErrNotPrimeBase {
+		t.Fatal("Sqrt should fail on a composite base", err)
+	}
+	if _, err := e.NewCRIInt64(4).IsQuadraticResidue(); err != ErrNotPrimeBase {
+		t.Fatal("IsQuadraticResidue should fail on a composite base", err)
+	}
+	if _, err := e.NewCRIInt64(4).CountSquareRoots(); err != ErrNotPrimeBase {
+		t.Fatal("CountSquareRoots should fail on a composite base", err)
+	}
+}
@@ -0,0 +1,182 @@
+package chinrem
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"sync"
+)
+
+// In tracked mode, each value carries an upper bound of the base 2 logarithm of its true integer magnitude,
+// in the signed interpretation. The bound is updated by each operation, without looking at the residues.
+// As long as the bound stays below log2(Limit/2), the residues represent the exact integer result, ie nothing wrapped.
+
+// trackSlack is added to the bounds after each operation, to absorb float rounding errors and keep them conservative.
+const trackSlack = 1e-9
+
+// Tracker creates and checks tracked values, for a given engine.
+// It records the first overflow, and can be shared between goroutines.
+type Tracker struct {
+	e          *CREngine
+	max        float64                     // log2(Limit/2), rounded down
+	onOverflow func(op string, x *Tracked) // optional callback
+	mu         sync.Mutex
+	err        error // first overflow
+}
+
+// Tracked is a CRI with an upper bound of its magnitude.
+type Tracked struct {
+	c     *CRI
+	bound float64 // |value| <= 2^bound
+	t     *Tracker
+}
+
+// NewTracker creates a Tracker for e. If onOverflow is not nil, it is called each time an operation
+// produces a value whose bound may reach Limit/2, with the name of the operation and the tracked result.
+func (e *CREngine) NewTracker(onOverflow func(op string, x *Tracked)) *Tracker {
+	return &Tracker{
+		e:          e,
+		max:        maxBound(e.limit),
+		onOverflow: onOverflow,
+	}
+}
+
+// Err returns nil if no tracked value ever overflowed, or an error wrapping ErrOverflow for the first overflow.
+func (t *Tracker) Err() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.err
+}
+
+// NewInt64 creates a tracked value, with the exact bound for v.
+func (t *Tracker) NewInt64(v int64) *Tracked {
+	return t.NewBig(big.NewInt(v))
+}
+
+// NewBig creates a tracked value, with the exact bound for v, using the signed interpretation.
+func (t *Tracker) NewBig(v *big.Int) *Tracked {
+	x := &Tracked{c: t.e.NewCRIBig(v), bound: bitBound(new(big.Int).Abs(v).BitLen()), t: t}
+	t.check("NewBig", x)
+	return x
+}
+
+// Track creates a tracked value from c, with the exact bound for its signed value.
+// The residues are copied.
+func (t *Tracker) Track(c *CRI) *Tracked {
+	x := &Tracked{c: t.e.NewCRI().Set(c), t: t}
+	x.bound = bitBound(t.e.NewCRI().Abs(c).BitLen())
+	t.check("Track", x)
+	return x
+}
+
+// maxBound is log2(Limit/2), rounded down, from the mantissa and exponent of Limit.
+// It is strictly below log2(Limit/2), so that a bound up to maxBound proves |value| < Limit/2.
+func maxBound(limit *big.Int) float64 {
+	mant := new(big.Float)
+	exp := new(big.Float).SetInt(limit).MantExp(mant) // Limit = mant * 2^exp, with 0.5 <= mant < 1
+	m, _ := new(big.Float).SetPrec(53).SetMode(big.ToZero).Set(mant).Float64()
+	return math.Nextafter(float64(exp-1)+math.Log2(m), math.Inf(-1))
+}
+
+// bitBound is the bound for a value of the provided bit length.
+func bitBound(n int) float64 {
+	if n == 0 {
+		return math.Inf(-1)
+	}
+	return float64(n)
+}
+
+// check records an overflow of x, produced by op.
+func (t *Tracker) check(op string, x *Tracked) {
+	if !x.Overflowed() {
+		return
+	}
+	t.mu.Lock()
+	if t.err == nil {
+		t.err = fmt.Errorf("%w : %s bound 2^%.2f may reach Limit/2", ErrOverflow, op, x.bound)
+	}
+	t.mu.Unlock()
+	if t.onOverflow != nil {
+		t.onOverflow(op, x)
+	}
+}
+
+// CRI returns the underlying CRI. It should not be modified directly.
+func (x *Tracked) CRI() *CRI {
+	return x.c
+}
+
+// Bound returns the base 2 logarithm of the bound of x, -Inf for 0.
+func (x *Tracked) Bound() float64 {
+	return x.bound
+}
+
+// Overflowed is true if the bound of x may reach Limit/2, so that x may no longer be the exact integer result.
+func (x *Tracked) Overflowed() bool {
+	return x.bound > x.t.max
+}
+
+// Add sets x to a + b, returning x.
+func (x *Tracked) Add(a, b *Tracked) *Tracked {
+	x.c.Add(a.c, b.c)
+	x.bound = addBound(a.bound, b.bound)
+	x.t.check("Add", x)
+	return x
+}
+
+// Sub sets x to a - b, returning x.
+func (x *Tracked) Sub(a, b *Tracked) *Tracked {
+	x.c.Sub(a.c, b.c)
+	x.bound = addBound(a.bound, b.bound)
+	x.t.check("Sub", x)
+	return x
+}
+
+// addBound is the bound of a sum, log2(2^a + 2^b).
+func addBound(a, b float64) float64 {
+	if a < b {
+		a, b = b, a
+	}
+	if math.IsInf(b, -1) {
+		return a
+	}
+	return a + math.Log2(1+math.Exp2(b-a)) + trackSlack
+}
+
+// Neg sets x to -a, returning x.
+func (x *Tracked) Neg(a *Tracked) *Tracked {
+	x.c.Neg(a.c)
+	x.bound = a.bound
+	return x
+}
+
+// Mul sets x to a * b, returning x.
+func (x *Tracked) Mul(a, b *Tracked) *Tracked {
+	x.c.Mul(a.c, b.c)
+	switch {
+	case math.IsInf(a.bound, -1) || math.IsInf(b.bound, -1): // exactly 0, even if the other bound overflowed
+		x.bound = math.Inf(-1)
+	default:
+		x.bound = a.bound + b.bound + trackSlack
+	}
+	x.t.check("Mul", x)
+	return x
+}
+
+// Exp sets x to a^n, returning x.
+// A negative n is handled as by ExpI, but the result is not an integer, so x is then always marked as overflowed.
+func (x *Tracked) Exp(a *Tracked, n int64) *Tracked {
+	bound := a.bound * float64(n)
+	switch {
+	case n < 0:
+		bound = math.Inf(1)
+	case n == 0:
+		bound = 0
+	case !math.IsInf(bound, 0):
+		bound += trackSlack
+	}
+	x.c.ExpI(a.c, n)
+	x.bound = bound
+	x.t.check("Exp", x)
+	return x
+}
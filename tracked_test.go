@@ -0,0 +1,92 @@
+package chinrem
+
+import (
+	"errors"
+	"math/big"
+	"math/rand"
+	"testing"
+)
+
+func TestTracked(t *testing.T) {
+	rd := rand.New(rand.NewSource(42))
+	e := NewCREngine(30)
+
+	overflows := 0
+	tr := e.NewTracker(func(op string, x *Tracked) { overflows++ })
+
+	// factorial, until it overflows
+	f, want := tr.NewInt64(1), big.NewInt(1)
+	for n := int64(1); !f.Overflowed(); n++ {
+		checkBig(t, "tracked factorial", f.CRI(), want)
+		f.Mul(f, tr.NewInt64(n))
+		want.Mul(want, big.NewInt(n))
+	}
+	if overflows != 1 || !errors.Is(tr.Err(), ErrOverflow) {
+		t.Fatalf("expected a single overflow, got %d, %v", overflows, tr.Err())
+	}
+	if want.Cmp(new(big.Int).Rsh(e.Limit(), 1)) <= 0 && f.Bound() > float64(e.Limit().BitLen()) {
+		t.Fatalf("bound %v is too loose for %v", f.Bound(), want)
+	}
+
+	// random signed computations : the signed value is exact until the bound overflows.
+	for i := 0; i < 100; i++ {
+		tr := e.NewTracker(nil)
+		x, xb := tr.NewInt64(rd.Int63n(2000)-1000), new(big.Int)
+		xb.Set(x.CRI().ToBigSigned())
+		for !x.Overflowed() {
+			if got := x.CRI().ToBigSigned(); got.Cmp(xb) != 0 {
+				t.Fatalf("tracked value : got %v, want %v", got, xb)
+			}
+			v := rd.Int63n(1<<20) - 1<<19
+			y := tr.NewInt64(v)
+			switch rd.Intn(5) {
+			case 0:
+				x.Add(x, y)
+				xb.Add(xb, big.NewInt(v))
+			case 1:
+				x.Sub(x, y)
+				xb.Sub(xb, big.NewInt(v))
+			case 2:
+				x.Neg(x)
+				xb.Neg(xb)
+			case 3:
+				x.Exp(x, 2)
+				xb.Mul(xb, xb)
+			default:
+				x.Mul(x, y)
+				xb.Mul(xb, big.NewInt(v))
+			}
+		}
+		if tr.Err() == nil {
+			t.Fatal("the overflow should be recorded")
+		}
+	}
+
+	// tracking an existing value
+	if x := tr.Track(e.NewCRIInt64(-1024)); x.Bound() != 11 {
+		t.Fatalf("unexpected bound %v for -1024", x.Bound())
+	}
+}
+
+func TestTrackedLimit(t *testing.T) {
+	e := NewCREngine(5) // Limit/2 is 1155
+	tr := e.NewTracker(nil)
+	if x := tr.NewInt64(1000); x.Overflowed() || tr.Err() != nil {
+		t.Fatalf("1000 fits, bound %v", x.Bound())
+	}
+	if x := tr.NewInt64(30); x.Mul(x, x).Overflowed() || tr.Err() != nil || x.CRI().ToBigSigned().Int64() != 900 {
+		t.Fatalf("900 fits, bound %v", x.Bound())
+	}
+	if x := tr.NewInt64(2048); !x.Overflowed() || !errors.Is(tr.Err(), ErrOverflow) {
+		t.Fatalf("2048 does not fit, bound %v", x.Bound())
+	}
+
+	// Limit/2 is a power of 2 : |value| <= 2^10 does not prove that it fits.
+	p, _ := NewCREngineModuli([]int64{1 << 11})
+	if x := p.NewTracker(nil).NewInt64(1023); !x.Overflowed() {
+		t.Fatalf("a bound of 2^%v should reach Limit/2", x.Bound())
+	}
+	if x := p.NewTracker(nil).NewInt64(511); x.Overflowed() {
+		t.Fatalf("511 fits, bound %v", x.Bound())
+	}
+}
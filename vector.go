@@ -0,0 +1,299 @@
+package chinrem
+
+import (
+	"math/big"
+	"math/bits"
+)
+
+// maxBarrett is the bound below which a modulus can use the Barrett reduction for products, ie a*b fits in an uint64.
+const maxBarrett = 1 << 32
+
+// CRIVector is a vector of CRI, sharing the same engine.
+// It is stored as a struct-of-arrays : one contiguous column of residues per prime of the base,
+// so that element-wise operations run tight, cache-friendly, inner loops over a single prime.
+type CRIVector struct {
+	n    int       // number of elements
+	cols [][]int64 // cols[i][j] is the residue of element j, modulo primes[i]
+	e    *CREngine
+}
+
+// Creates a new CRIVector of n elements, all set to 0.
+// All the columns share a single allocation.
+func (e *CREngine) NewCRIVector(n int) *CRIVector {
+	v := new(CRIVector)
+	v.n, v.e = n, e
+	v.cols = make([][]int64, e.size)
+	all := make([]int64, n*e.size)
+	for i := range v.cols {
+		v.cols[i] = all[i*n : (i+1)*n : (i+1)*n]
+	}
+	return v
+}
+
+// Len is the number of elements of v.
+func (v *CRIVector) Len() int {
+	return v.n
+}
+
+// checkLen panics if the lengths do not match.
+func (v *CRIVector) checkLen(n int) {
+	if n != v.n {
+		panic("CRIVector lengths do not match")
+	}
+}
+
+// Get copies element j of v into c, returning c.
+func (v *CRIVector) Get(j int, c *CRI) *CRI {
+	for i, col := range v.cols {
+		c.rm[i] = col[j]
+	}
+	return c
+}
+
+// Set copies c into element j of v, returning v.
+// No normalization is performed, if c was not already normalized.
+func (v *CRIVector) Set(j int, c *CRI) *CRIVector {
+	for i, col := range v.cols {
+		col[j] = c.rm[i]
+	}
+	return v
+}
+
+// Gather copies src[j] into element j of v, for all j, returning v.
+// Panic if length do not match.
+func (v *CRIVector) Gather(src []*CRI) *CRIVector {
+	v.checkLen(len(src))
+	for i, col := range v.cols {
+		for j, c := range src {
+			col[j] = c.rm[i]
+		}
+	}
+	return v
+}
+
+// Scatter copies element j of v into dst[j], for all j.
+// Panic if length do not match.
+func (v *CRIVector) Scatter(dst []*CRI) {
+	v.checkLen(len(dst))
+	for i, col := range v.cols {
+		for j, c := range dst {
+			c.rm[i] = col[j]
+		}
+	}
+}
+
+// SetBigs sets element j of v to values[j], for all j, returning v.
+// Panic if length do not match.
+// v is normalized.
+func (v *CRIVector) SetBigs(values []*big.Int) *CRIVector {
+	v.checkLen(len(values))
+	c := v.e.NewCRI()
+	for j, b := range values {
+		v.Set(j, c.SetBig(b))
+	}
+	return v
+}
+
+// ToBigs returns the big.Int representation of all the elements of v.
+func (v *CRIVector) ToBigs() []*big.Int {
+	res := make([]*big.Int, v.n)
+	c := v.e.NewCRI()
+	for j := range res {
+		res[j] = v.Get(j, c).ToBig()
+	}
+	return res
+}
+
+// columns runs f on each column i, sharding the columns across the workers of a parallel engine.
+func (v *CRIVector) columns(f func(i int, m int64)) {
+	v.columnsErr(func(i int, m int64) error {
+		f(i, m)
+		return nil
+	})
+}
+
+// columnsErr is the same as columns, for a function that can fail. It returns one of the errors, if any.
+func (v *CRIVector) columnsErr(f func(i int, m int64) error) error {
+	run := func(lo, hi int) error {
+		for i := lo; i < hi; i++ {
+			if err := f(i, v.e.primes[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if v.e.serial(v.n * v.e.size) {
+		return run(0, v.e.size)
+	}
+	return v.e.shardErr(v.e.size, run)
+}
+
+// Add computes a+b element-wise, storing the result in v, returning v.
+// Normalization is assumed, and v is normalized.
+func (v *CRIVector) Add(a, b *CRIVector) *CRIVector {
+	v.checkLen(a.n)
+	v.checkLen(b.n)
+	v.columns(func(i int, m int64) {
+		r, x, y := v.cols[i], a.cols[i], b.cols[i]
+		x, y = x[:len(r)], y[:len(r)]
+		for j := range r {
+			s := x[j] + y[j]
+			if s >= m {
+				s -= m
+			}
+			r[j] = s
+		}
+	})
+	return v
+}
+
+// Sub computes a-b element-wise, storing the result in v, returning v.
+// Normalization is assumed, and v is normalized.
+func (v *CRIVector) Sub(a, b *CRIVector) *CRIVector {
+	v.checkLen(a.n)
+	v.checkLen(b.n)
+	v.columns(func(i int, m int64) {
+		r, x, y := v.cols[i], a.cols[i], b.cols[i]
+		x, y = x[:len(r)], y[:len(r)]
+		for j := range r {
+			s := x[j] - y[j]
+			if s < 0 {
+				s += m
+			}
+			r[j] = s
+		}
+	})
+	return v
+}
+
+// Mul computes a*b element-wise, storing the result in v, returning v.
+// Normalization is assumed, and v is normalized.
+func (v *CRIVector) Mul(a, b *CRIVector) *CRIVector {
+	v.checkLen(a.n)
+	v.checkLen(b.n)
+	v.columns(func(i int, m int64) {
+		r, x, y := v.cols[i], a.cols[i], b.cols[i]
+		x, y = x[:len(r)], y[:len(r)]
+		if m < maxBarrett {
+			um, mu := uint64(m), ^uint64(0)/uint64(m)
+			for j := range r {
+				r[j] = int64(reduce(uint64(x[j])*uint64(y[j]), um, mu))
+			}
+			return
+		}
+		for j := range r {
+			r[j] = mulmod(x[j], y[j], m)
+		}
+	})
+	return v
+}
+
+// AddScalar computes a+s for each element of a, storing the result in v, returning v.
+// Normalization is assumed, and v is normalized.
+func (v *CRIVector) AddScalar(a *CRIVector, s *CRI) *CRIVector {
+	v.checkLen(a.n)
+	v.columns(func(i int, m int64) {
+		r, x, si := v.cols[i], a.cols[i][:v.n], s.rm[i]
+		for j := range r {
+			t := x[j] + si
+			if t >= m {
+				t -= m
+			}
+			r[j] = t
+		}
+	})
+	return v
+}
+
+// SubScalar computes a-s for each element of a, storing the result in v, returning v.
+// Normalization is assumed, and v is normalized.
+func (v *CRIVector) SubScalar(a *CRIVector, s *CRI) *CRIVector {
+	v.checkLen(a.n)
+	v.columns(func(i int, m int64) {
+		r, x, si := v.cols[i], a.cols[i][:v.n], s.rm[i]
+		for j := range r {
+			t := x[j] - si
+			if t < 0 {
+				t += m
+			}
+			r[j] = t
+		}
+	})
+	return v
+}
+
+// MulScalar computes a*s for each element of a, storing the result in v, returning v.
+// Normalization is assumed, and v is normalized.
+func (v *CRIVector) MulScalar(a *CRIVector, s *CRI) *CRIVector {
+	v.checkLen(a.n)
+	v.columns(func(i int, m int64) {
+		r, x, si := v.cols[i], a.cols[i][:v.n], s.rm[i]
+		if m < maxBarrett {
+			um, mu := uint64(m), ^uint64(0)/uint64(m)
+			for j := range r {
+				r[j] = int64(reduce(uint64(x[j])*uint64(si), um, mu))
+			}
+			return
+		}
+		for j := range r {
+			r[j] = mulmod(x[j], si, m)
+		}
+	})
+	return v
+}
+
+// Inv computes the inverse of each element of a, storing the result in v.
+// If an element has no inverse, return ErrNotInversible, and v is left in an undefined state.
+func (v *CRIVector) Inv(a *CRIVector) error {
+	v.checkLen(a.n)
+	return v.columnsErr(func(i int, m int64) error {
+		r, x := v.cols[i], a.cols[i][:v.n]
+		for j := range r {
+			g, u, _ := gcd(x[j], m)
+			if g != 1 {
+				return ErrNotInversible
+			}
+			if u < 0 {
+				u += m
+			}
+			r[j] = u
+		}
+		return nil
+	})
+}
+
+// Exp computes a^n for each element of a, where the exponent is a big.Int, storing the result in v, returning v.
+// a^0 is 1, for any a.
+// For a negative n, the result is the inverse of a^-n. It panics with ErrNotInversible if an element has no inverse.
+// Normalization is assumed, and v is normalized.
+func (v *CRIVector) Exp(a *CRIVector, n *big.Int) *CRIVector {
+	v.checkLen(a.n)
+	if n.Sign() < 0 {
+		if err := v.Inv(a); err != nil {
+			panic(err)
+		}
+		a = v
+	}
+	v.columns(func(i int, m int64) {
+		k := bigModInt64(n, smallExp)
+		if n.BitLen() > 6 { // n >= smallExp
+			k = v.e.laneExp(i, bigModInt64(n, v.e.expOrd[i]))
+		}
+		r := v.cols[i]
+		x := append([]int64(nil), a.cols[i][:v.n]...) // a may be aliased by v
+		for j := range r {
+			r[j] = 1 % m
+		}
+		for b := bits.Len64(uint64(k)) - 1; b >= 0; b-- {
+			for j := range r {
+				r[j] = mulmod(r[j], r[j], m)
+			}
+			if (k>>b)&1 == 1 {
+				for j := range r {
+					r[j] = mulmod(r[j], x[j], m)
+				}
+			}
+		}
+	})
+	return v
+}
@@ -0,0 +1,87 @@
+package chinrem
+
+import (
+	"errors"
+	"math/big"
+	"math/rand"
+	"testing"
+)
+
+func TestVector(t *testing.T) {
+	rd := rand.New(rand.NewSource(42))
+
+	for _, e := range []*CREngine{NewCREngine(10), NewCREngine62(3), NewCREngine(30).Parallel(3, 1)} {
+		const n = 40
+		as, bs := make([]*CRI, n), make([]*CRI, n)
+		for j := range as {
+			as[j], bs[j] = e.NewCRIRand(rd), e.NewCRIRand(rd)
+		}
+		s := e.NewCRIRand(rd)
+		a, b, v := e.NewCRIVector(n).Gather(as), e.NewCRIVector(n).Gather(bs), e.NewCRIVector(n)
+		if v.Len() != n {
+			t.Fatal("unexpected length", v.Len())
+		}
+
+		check := func(op string, want func(c *CRI, j int) *CRI) {
+			t.Helper()
+			got := make([]*CRI, n)
+			for j := range got {
+				got[j] = e.NewCRI()
+			}
+			v.Scatter(got)
+			for j := range got {
+				if w := want(e.NewCRI(), j); !got[j].Equal(w) {
+					t.Fatalf("%s, element %d : got %v, want %v", op, j, got[j], w)
+				}
+			}
+		}
+
+		v.Add(a, b)
+		check("Add", func(c *CRI, j int) *CRI { return c.Add(as[j], bs[j]) })
+		v.Sub(a, b)
+		check("Sub", func(c *CRI, j int) *CRI { return c.Sub(as[j], bs[j]) })
+		v.Mul(a, b)
+		check("Mul", func(c *CRI, j int) *CRI { return c.Mul(as[j], bs[j]) })
+		v.AddScalar(a, s)
+		check("AddScalar", func(c *CRI, j int) *CRI { return c.Add(as[j], s) })
+		v.SubScalar(a, s)
+		check("SubScalar", func(c *CRI, j int) *CRI { return c.Sub(as[j], s) })
+		v.MulScalar(a, s)
+		check("MulScalar", func(c *CRI, j int) *CRI { return c.Mul(as[j], s) })
+		nn := big.NewInt(rd.Int63())
+		v.Exp(a, nn)
+		check("Exp", func(c *CRI, j int) *CRI { return c.Exp(as[j], nn) })
+		v.Exp(v, nn) // aliasing
+		check("Exp", func(c *CRI, j int) *CRI { return c.Exp(c.Exp(as[j], nn), nn) })
+
+		// Inv : element 0 is 0, and is not inversible.
+		if err := v.Inv(a.Set(0, e.NewCRI())); !errors.Is(err, ErrNotInversible) {
+			t.Fatal("Inv should fail", err)
+		}
+		inv := e.NewCRIInt64(1)
+		for j := range as {
+			as[j].Set(s)
+			a.Set(j, s)
+		}
+		if inv.Inv(s) == nil {
+			if err := v.Inv(a); err != nil {
+				t.Fatal(err)
+			}
+			check("Inv", func(c *CRI, j int) *CRI { return inv })
+		}
+
+		// big.Int conversions
+		bigs := b.ToBigs()
+		for j, bb := range bigs {
+			if bb.Cmp(bs[j].ToBig()) != 0 {
+				t.Fatalf("ToBigs, element %d : got %v, want %v", j, bb, bs[j].ToBig())
+			}
+			bb.Add(bb, big.NewInt(int64(j)))
+		}
+		v.SetBigs(bigs)
+		check("SetBigs", func(c *CRI, j int) *CRI { return c.AddInt64(bs[j], int64(j)) })
+		if v.Get(3, e.NewCRI()).ToBig().Cmp(bigs[3].Mod(bigs[3], e.Limit())) != 0 {
+			t.Fatal("Get failed")
+		}
+	}
+}
@@ -1,3 +1,3 @@
 package chinrem
 
-const Version = "0.2.1"
+const Version = "0.3.0"